@@ -0,0 +1,33 @@
+package cleed
+
+import "github.com/spf13/cobra"
+
+func (r *Root) initBackup() {
+	cmd := &cobra.Command{
+		Use:   "backup [file]",
+		Short: "Backup the config, lists and item state",
+		Long: `Backup the config, lists, item state, starred and archived items to a single archive
+
+Examples:
+  # Backup to a file
+  cleed backup cleed.tar.gz
+
+  # Backup including the cache
+  cleed backup cleed.tar.gz --include-cache
+
+  # Restore from a file
+  cleed restore cleed.tar.gz
+`,
+		RunE: r.RunBackup,
+		Args: cobra.ExactArgs(1),
+	}
+
+	flags := cmd.Flags()
+	flags.Bool("include-cache", false, "include the cache in the backup")
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunBackup(cmd *cobra.Command, args []string) error {
+	return r.feed.Backup(args[0], cmd.Flag("include-cache").Changed)
+}
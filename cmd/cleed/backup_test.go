@@ -0,0 +1,280 @@
+package cleed
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Backup_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	setupCacheTest(t, storage)
+	err := storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {"guid": {FirstSeen: defaultCurrentTime, Read: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.AddStarred([]*_storage.StarredItem{{ID: "guid", FeedURL: "https://example.com", Title: "Item"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveFeedArchive("https://example.com", []*gofeed.Item{{Title: "Item", GUID: "guid"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := path.Join(t.TempDir(), "cleed.tar.gz")
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "backup", file, "--include-cache"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "backup saved to "+file+"\n", out.String())
+
+	localStorageCleanup(t, storage)
+	storage = _storage.NewLocalStorage("cleed_test", timeMock)
+	feed = internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "restore", file}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "restored backup from "+file+" (created at 2024-01-01 00:00:00)\n", out.String())
+
+	lists, err := storage.LoadLists()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default", "test"}, lists)
+	items, err := storage.GetFeedsFromList("test")
+	assert.NoError(t, err)
+	assert.Equal(t, []*_storage.ListItem{
+		{AddedAt: time.Unix(defaultCurrentTime.Unix(), 0), Address: "https://test.com"},
+	}, items)
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.True(t, states["https://example.com"]["guid"].Read)
+	starred, err := storage.LoadStarred()
+	assert.NoError(t, err)
+	assert.Len(t, starred, 1)
+	archived, err := storage.LoadFeedArchive("https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []*gofeed.Item{{Title: "Item", GUID: "guid"}}, archived)
+	cacheInfo, err := storage.LoadCacheInfo()
+	assert.NoError(t, err)
+	assert.Len(t, cacheInfo, 3)
+	fc, err := storage.OpenFeedCache("https://test.com")
+	assert.NoError(t, err)
+	b := new(bytes.Buffer)
+	_, err = b.ReadFrom(fc)
+	fc.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "<rss>https://test.com</rss>", b.String())
+}
+
+func Test_Restore_Migrate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	file := path.Join(t.TempDir(), "cleed.tar.gz")
+	writeBackupFixture(t, file, map[string]string{
+		"manifest.json":        `{"version":"0.1.0","schema":{},"createdAt":"2023-12-31T00:00:00Z"}`,
+		"config/config.json":   `{"version":"0.1.0","styling":7,"colorMap":{}}`,
+		"config/lists/default": fmt.Sprintf("%d %s\n\n%d %s\n", defaultCurrentTime.Unix(), "https://example.com", defaultCurrentTime.Unix(), "https://example.com"),
+	})
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "restore", file}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "restored backup from "+file+" (created at 2023-12-31 00:00:00)\n", out.String())
+
+	config, err := storage.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, _storage.LatestSchema(), config.Schema)
+	assert.Equal(t, uint8(0), config.Styling)
+	items, err := storage.GetFeedsFromList("default")
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func Test_Restore_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	dir := t.TempDir()
+	newer := path.Join(dir, "newer.tar.gz")
+	writeBackupFixture(t, newer, map[string]string{
+		"manifest.json":      `{"version":"9.0.0","schema":{"lists":99}}`,
+		"config/config.json": `{"version":"9.0.0"}`,
+	})
+	unknown := path.Join(dir, "unknown.tar.gz")
+	writeBackupFixture(t, unknown, map[string]string{
+		"manifest.json":     `{"version":"0.1.0","schema":{}}`,
+		"config/../../evil": "evil",
+	})
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "restore", newer}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, fmt.Sprintf("failed to restore backup: lists schema version 99 of the backup is newer than the supported version %d", _storage.LatestSchema()["lists"]))
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "restore", unknown}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "failed to restore backup: invalid backup entry: config/../../evil")
+}
+
+func Test_Restore_Truncated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	setupCacheTest(t, storage)
+
+	// Random content so the archive cannot be compressed much and the
+	// truncation happens after the manifest
+	r := rand.New(rand.NewSource(1))
+	content := make([]byte, 64*1024)
+	for i := range content {
+		content[i] = byte('a' + r.Intn(26))
+	}
+	file := path.Join(t.TempDir(), "cleed.tar.gz")
+	writeBackupFixture(t, file, map[string]string{
+		"manifest.json":      `{"version":"0.1.0","schema":{},"cache":true}`,
+		"config/lists/other": fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://other.com"),
+		"cache/feed_large":   string(content),
+	})
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(file, b[:len(b)/2], 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "restore", file}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "failed to restore backup: invalid backup: unexpected EOF")
+
+	lists, err := storage.LoadLists()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default", "test"}, lists)
+	cacheInfo, err := storage.LoadCacheInfo()
+	assert.NoError(t, err)
+	assert.Len(t, cacheInfo, 3)
+	fc, err := storage.OpenFeedCache("https://test.com")
+	assert.NoError(t, err)
+	fc.Close()
+}
+
+// writeBackupFixture writes the entries to a backup archive, the manifest first.
+func writeBackupFixture(t *testing.T, name string, entries map[string]string) {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(zw)
+	names := []string{"manifest.json"}
+	for entry := range entries {
+		if entry != "manifest.json" {
+			names = append(names, entry)
+		}
+	}
+	for _, entry := range names {
+		err := tw.WriteHeader(&tar.Header{Name: entry, Mode: 0600, Size: int64(len(entries[entry]))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tw.Write([]byte(entries[entry]))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(name, buf.Bytes(), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
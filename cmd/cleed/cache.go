@@ -0,0 +1,66 @@
+package cleed
+
+import (
+	"github.com/radulucut/cleed/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func (r *Root) initCache() {
+	cmd := &cobra.Command{
+		Use:   "cache [feed]",
+		Short: "Show or manage the cache",
+		Long: `Show or manage the cache
+
+Examples:
+  # Show the on-disk size of the cache of each feed
+  cleed cache
+
+  # Clear the cache of a feed. Its archived items are kept
+  cleed cache https://example.com/feed.xml --clear
+
+  # Clear the cache of all feeds in a list
+  cleed cache --list mylist --clear
+
+  # Clear the whole cache
+  cleed cache --clear --all
+
+  # Print the cached body of a feed
+  cleed cache https://example.com/feed.xml --dump
+
+  # Remove the cache of feeds that are not in any list
+  cleed cache --prune
+`,
+		RunE: r.RunCache,
+		Args: cobra.MaximumNArgs(1),
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("list", "L", "", "the list of feeds to clear the cache for")
+	flags.Bool("clear", false, "clear the cache of a feed, a list or all feeds")
+	flags.Bool("all", false, "clear the cache of all feeds")
+	flags.Bool("dump", false, "print the cached body of a feed")
+	flags.Bool("prune", false, "remove the cache of feeds that are not in any list")
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunCache(cmd *cobra.Command, args []string) error {
+	if cmd.Flag("clear").Changed {
+		list := cmd.Flag("list").Value.String()
+		all := cmd.Flag("all").Changed
+		if len(args) == 0 && list == "" && !all {
+			return utils.NewInternalError("please provide a feed, a list or --all")
+		}
+		return r.feed.ClearCache(args, list, all)
+	}
+	if cmd.Flag("dump").Changed {
+		if len(args) == 0 {
+			return utils.NewInternalError("please provide a feed")
+		}
+		return r.feed.DumpCache(args[0])
+	}
+	if cmd.Flag("prune").Changed {
+		return r.feed.PruneCache()
+	}
+	return r.feed.ShowCacheSizes()
+}
@@ -0,0 +1,358 @@
+package cleed
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func setupCacheTest(t *testing.T, storage *_storage.LocalStorage) string {
+	storage.Init("0.1.0")
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://example.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "test"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://test.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, u := range []string{"https://example.com", "https://test.com", "https://unfollowed.com"} {
+		err = storage.SaveFeedCache(bytes.NewBufferString("<rss>"+u+"</rss>"), u)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = storage.SaveCacheInfo(map[string]*_storage.CacheInfoItem{
+		"https://example.com":    {URL: "https://example.com", LastFetch: time.Unix(defaultCurrentTime.Unix(), 0), FetchAfter: time.Unix(0, 0)},
+		"https://test.com":       {URL: "https://test.com", LastFetch: time.Unix(defaultCurrentTime.Unix(), 0), FetchAfter: time.Unix(0, 0)},
+		"https://unfollowed.com": {URL: "https://unfollowed.com", LastFetch: time.Unix(defaultCurrentTime.Unix(), 0), FetchAfter: time.Unix(0, 0)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path.Join(cacheDir, "cleed_test")
+}
+
+func Test_Cache_Sizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	setupCacheTest(t, storage)
+
+	items := make([]*gofeed.Item, 10)
+	for i := range items {
+		items[i] = &gofeed.Item{Title: "Item", Link: "https://example.com/item"}
+	}
+	err := storage.SaveFeedArchive("https://example.com", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveFeedSnapshot("https://example.com", &gofeed.Feed{Title: "Feed", Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "cache"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `URL                     Size
+https://example.com     980 B
+https://test.com        52 B
+https://unfollowed.com  58 B
+Total: 1.1 KB
+`, out.String())
+}
+
+func Test_Cache_Clear_Feed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	cacheDir := setupCacheTest(t, storage)
+
+	archived := []*gofeed.Item{{Title: "Item", Link: "https://example.com/item"}}
+	err := storage.SaveFeedArchive("https://example.com", archived)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "cache", "https://example.com", "--clear"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "cleared cache of 1 feed\n", out.String())
+
+	assert.NoFileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
+	assert.FileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://test.com")))
+	cacheInfo, err := storage.LoadCacheInfo()
+	assert.NoError(t, err)
+	assert.Len(t, cacheInfo, 2)
+	assert.Nil(t, cacheInfo["https://example.com"])
+
+	items, err := storage.LoadFeedArchive("https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, archived, items)
+}
+
+func Test_Cache_Clear_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	cacheDir := setupCacheTest(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "cache", "--list", "test", "--clear"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "cleared cache of 1 feed\n", out.String())
+
+	assert.FileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
+	assert.NoFileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://test.com")))
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "cache", "--list", "missing", "--clear"}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "no feeds found in list: missing")
+
+	assert.FileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
+}
+
+func Test_Cache_Clear_All(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	cacheDir := setupCacheTest(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "cache", "--clear"}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "please provide a feed, a list or --all")
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "cache", "--clear", "--all"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "cache was cleared\n", out.String())
+
+	files, err := os.ReadDir(cacheDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 0)
+}
+
+func Test_Cache_Clear_All_Profiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer func() {
+		storage.SetProfile("work")
+		localStorageCleanup(t, storage)
+		storage.SetProfile("")
+		localStorageCleanup(t, storage)
+	}()
+
+	cacheDir := setupCacheTest(t, storage)
+
+	err := storage.SetProfile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.Init("0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveFeedCache(bytes.NewBufferString("<rss>https://work.com</rss>"), "https://work.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.SetProfile("")
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "cache", "--clear", "--all"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "cache was cleared\n", out.String())
+
+	assert.NoFileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
+	assert.FileExists(t, path.Join(cacheDir, "profiles", "work", "feed_"+url.QueryEscape("https://work.com")))
+
+	err = storage.ClearAll()
+	assert.NoError(t, err)
+	assert.FileExists(t, path.Join(cacheDir, "profiles", "work", "feed_"+url.QueryEscape("https://work.com")))
+}
+
+func Test_Cache_Dump(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	setupCacheTest(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "cache", "https://test.com", "--dump"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "<rss>https://test.com</rss>", out.String())
+}
+
+func Test_Cache_Prune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	cacheDir := setupCacheTest(t, storage)
+
+	err := storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {"guid": {FirstSeen: defaultCurrentTime}},
+		"https://removed.com": {"guid": {FirstSeen: defaultCurrentTime}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "cache", "--prune"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `https://removed.com
+https://unfollowed.com
+pruned cache of 2 feeds
+`, out.String())
+
+	assert.FileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
+	assert.FileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://test.com")))
+	assert.NoFileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://unfollowed.com")))
+	cacheInfo, err := storage.LoadCacheInfo()
+	assert.NoError(t, err)
+	assert.Len(t, cacheInfo, 2)
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.Len(t, states, 1)
+	assert.NotNil(t, states["https://example.com"])
+}
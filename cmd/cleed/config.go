@@ -1,6 +1,11 @@
 package cleed
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/radulucut/cleed/internal"
+	"github.com/radulucut/cleed/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -29,8 +34,29 @@ Examples:
   # Display color range. Useful for finding colors to map
   cleed config --color-range
 
+  # Set the palette of colors assigned to feeds
+  cleed config --palette=33,69,208
+
+  # Use the default palette
+  cleed config --palette=
+
   # Enable run summary
   cleed config --summary=1
+
+  # Keep archived items for 30 days
+  cleed config --archive-max-age=30d
+
+  # Keep at most 500 items per feed in the archive
+  cleed config --archive-max-items=500
+
+  # Set the git repository used by cleed sync
+  cleed config --sync-repo ~/cleed-lists
+
+  # Display items using the compact built-in template by default
+  cleed config --template compact
+
+  # Use the default output
+  cleed config --template=
 `,
 		RunE: r.RunConfig,
 	}
@@ -40,6 +66,11 @@ Examples:
 	flags.Uint8("summary", 0, "disable or enable summary (0: disable, 1: enable)")
 	flags.String("map-colors", "", "map colors to other colors, e.g. 0:230,1:213. Use --color-range to check available colors")
 	flags.Bool("color-range", false, "display color range. Useful for finding colors to map")
+	flags.String("palette", "", "colors assigned to feeds, e.g. 33,69,208. Empty for the default palette")
+	flags.String("archive-max-age", "", "remove archived items older than this duration, e.g. 30d. Empty for no limit (default "+storage.DefaultArchiveMaxAge+")")
+	flags.Uint("archive-max-items", 0, "maximum number of items to keep per feed in the archive. 0 for no limit (default "+strconv.Itoa(storage.DefaultArchiveMaxItems)+")")
+	flags.String("sync-repo", "", "path to the git repository used by sync. Empty to unset")
+	flags.String("template", "", "default item template: "+strings.Join(internal.BuiltinTemplateNames(), ", ")+", a file or an inline template. Empty for the default output")
 
 	r.Cmd.AddCommand(cmd)
 }
@@ -62,6 +93,25 @@ func (r *Root) RunConfig(cmd *cobra.Command, args []string) error {
 	if cmd.Flag("map-colors").Changed {
 		return r.feed.UpdateColorMap(cmd.Flag("map-colors").Value.String())
 	}
+	if cmd.Flag("palette").Changed {
+		return r.feed.SetPalette(cmd.Flag("palette").Value.String())
+	}
+	if cmd.Flag("archive-max-age").Changed {
+		return r.feed.SetArchiveMaxAge(cmd.Flag("archive-max-age").Value.String())
+	}
+	if cmd.Flag("archive-max-items").Changed {
+		maxItems, err := cmd.Flags().GetUint("archive-max-items")
+		if err != nil {
+			return err
+		}
+		return r.feed.SetArchiveMaxItems(maxItems)
+	}
+	if cmd.Flag("sync-repo").Changed {
+		return r.feed.SetSyncRepo(cmd.Flag("sync-repo").Value.String())
+	}
+	if cmd.Flag("template").Changed {
+		return r.feed.SetTemplate(cmd.Flag("template").Value.String())
+	}
 	if cmd.Flag("color-range").Changed {
 		r.feed.DisplayColorRange()
 		return nil
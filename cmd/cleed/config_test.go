@@ -38,16 +38,24 @@ func Test_Config(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, `Styling: enabled
 Color map:
+Palette: 1 2 3 4 5 6 9 10 11 12 13 14 (default)
 Summary: disabled
+Archive max age: 90d
+Archive max items: 1000
+Sync repository: not set
+Template: default
 `, out.String())
 
 	config, err := storage.LoadConfig()
 	assert.NoError(t, err)
 	expectedConfig := &_storage.Config{
-		Version:  "0.1.0",
-		LastRun:  time.Time{},
-		Styling:  0,
-		ColorMap: make(map[uint8]uint8),
+		Version:         "0.1.0",
+		LastRun:         time.Time{},
+		Styling:         0,
+		ColorMap:        make(map[uint8]uint8),
+		Schema:          _storage.LatestSchema(),
+		ArchiveMaxAge:   _storage.DefaultArchiveMaxAge,
+		ArchiveMaxItems: _storage.DefaultArchiveMaxItems,
 	}
 	assert.Equal(t, expectedConfig, config)
 }
@@ -79,10 +87,13 @@ func Test_Config_Styling(t *testing.T) {
 	config, err := storage.LoadConfig()
 	assert.NoError(t, err)
 	expectedConfig := &_storage.Config{
-		Version:  "0.1.0",
-		LastRun:  time.Time{},
-		Styling:  2,
-		ColorMap: make(map[uint8]uint8),
+		Version:         "0.1.0",
+		LastRun:         time.Time{},
+		Styling:         2,
+		ColorMap:        make(map[uint8]uint8),
+		Schema:          _storage.LatestSchema(),
+		ArchiveMaxAge:   _storage.DefaultArchiveMaxAge,
+		ArchiveMaxItems: _storage.DefaultArchiveMaxItems,
 	}
 	assert.Equal(t, expectedConfig, config)
 }
@@ -114,11 +125,14 @@ func Test_Config_Summary(t *testing.T) {
 	config, err := storage.LoadConfig()
 	assert.NoError(t, err)
 	expectedConfig := &_storage.Config{
-		Version:  "0.1.0",
-		LastRun:  time.Time{},
-		Styling:  0,
-		Summary:  1,
-		ColorMap: make(map[uint8]uint8),
+		Version:         "0.1.0",
+		LastRun:         time.Time{},
+		Styling:         0,
+		Summary:         1,
+		ColorMap:        make(map[uint8]uint8),
+		Schema:          _storage.LatestSchema(),
+		ArchiveMaxAge:   _storage.DefaultArchiveMaxAge,
+		ArchiveMaxItems: _storage.DefaultArchiveMaxItems,
 	}
 	assert.Equal(t, expectedConfig, config)
 }
@@ -150,10 +164,13 @@ func Test_Config_MapColors(t *testing.T) {
 	config, err := storage.LoadConfig()
 	assert.NoError(t, err)
 	expectedConfig := &_storage.Config{
-		Version:  "0.1.0",
-		LastRun:  time.Time{},
-		Styling:  0,
-		ColorMap: map[uint8]uint8{1: 2, 3: 4},
+		Version:         "0.1.0",
+		LastRun:         time.Time{},
+		Styling:         0,
+		ColorMap:        map[uint8]uint8{1: 2, 3: 4},
+		Schema:          _storage.LatestSchema(),
+		ArchiveMaxAge:   _storage.DefaultArchiveMaxAge,
+		ArchiveMaxItems: _storage.DefaultArchiveMaxItems,
 	}
 	assert.Equal(t, expectedConfig, config)
 }
@@ -192,10 +209,13 @@ func Test_Config_MapColors_RemoveColorMapping(t *testing.T) {
 	config, err = storage.LoadConfig()
 	assert.NoError(t, err)
 	expectedConfig := &_storage.Config{
-		Version:  "0.1.0",
-		LastRun:  time.Time{},
-		Styling:  0,
-		ColorMap: map[uint8]uint8{3: 4},
+		Version:         "0.1.0",
+		LastRun:         time.Time{},
+		Styling:         0,
+		ColorMap:        map[uint8]uint8{3: 4},
+		Schema:          _storage.LatestSchema(),
+		ArchiveMaxAge:   _storage.DefaultArchiveMaxAge,
+		ArchiveMaxItems: _storage.DefaultArchiveMaxItems,
 	}
 	assert.Equal(t, expectedConfig, config)
 }
@@ -234,10 +254,13 @@ func Test_Config_MapColors_ClearColorMapping(t *testing.T) {
 	config, err = storage.LoadConfig()
 	assert.NoError(t, err)
 	expectedConfig := &_storage.Config{
-		Version:  "0.1.0",
-		LastRun:  time.Time{},
-		Styling:  0,
-		ColorMap: map[uint8]uint8{},
+		Version:         "0.1.0",
+		LastRun:         time.Time{},
+		Styling:         0,
+		ColorMap:        map[uint8]uint8{},
+		Schema:          _storage.LatestSchema(),
+		ArchiveMaxAge:   _storage.DefaultArchiveMaxAge,
+		ArchiveMaxItems: _storage.DefaultArchiveMaxItems,
 	}
 	assert.Equal(t, expectedConfig, config)
 }
@@ -279,3 +302,119 @@ func Test_Config_ColorRange(t *testing.T) {
 	expectedOutput += "\n"
 	assert.Equal(t, expectedOutput, out.String())
 }
+
+func Test_Config_ArchiveRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "config", "--archive-max-age", "30d"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "archive max age was updated\n", out.String())
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "config", "--archive-max-items", "500"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "archive max items was updated\n", out.String())
+
+	config, err := storage.LoadConfig()
+	assert.NoError(t, err)
+	expectedConfig := &_storage.Config{
+		Version:         "0.1.0",
+		LastRun:         time.Time{},
+		Styling:         0,
+		ColorMap:        make(map[uint8]uint8),
+		Schema:          _storage.LatestSchema(),
+		ArchiveMaxAge:   "30d",
+		ArchiveMaxItems: 500,
+	}
+	assert.Equal(t, expectedConfig, config)
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "config", "--archive-max-age", "30x"}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "invalid value for archive max age: 30x")
+}
+
+func Test_Config_Palette(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "config", "--palette", "33,69,208"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "palette updated\n", out.String())
+
+	config, err := storage.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint8{33, 69, 208}, config.Palette)
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "config"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Palette: 33 69 208\n")
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "config", "--palette", "33,256"}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "failed to parse color: 256")
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "config", "--palette="}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	config, err = storage.LoadConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config.Palette)
+}
@@ -0,0 +1,34 @@
+package cleed
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func (r *Root) initDuplicates() {
+	cmd := &cobra.Command{
+		Use:   "duplicates",
+		Short: "Find feeds that are followed under different URLs",
+		Long: `Find feeds that are followed under different URLs
+
+URLs are compared without the scheme, a "www." prefix, a trailing slash, the fragment and tracking parameters (e.g. utm_source).
+
+Examples:
+  # Show duplicate feeds across all lists
+  cleed duplicates
+
+  # Replace the duplicates with the feed that is kept (https, then the one added first)
+  cleed duplicates --merge
+`,
+		RunE: r.RunDuplicates,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.Bool("merge", false, "replace the duplicates in all lists with the feed that is kept")
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunDuplicates(cmd *cobra.Command, args []string) error {
+	return r.feed.Duplicates(cmd.Flag("merge").Changed)
+}
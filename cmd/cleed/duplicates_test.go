@@ -0,0 +1,146 @@
+package cleed
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Duplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+	storage.Init("0.1.0")
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.WriteFile(path.Join(listsDir, "default"), []byte(fmt.Sprintf("%d %s\n%d %s\n%d %s\n",
+		defaultCurrentTime.Unix()-100, "http://example.com/feed",
+		defaultCurrentTime.Unix(), "https://www.example.com/feed/",
+		defaultCurrentTime.Unix(), "https://test.com",
+	)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "tech"), []byte(fmt.Sprintf("%d %s\n%d %s %s\n",
+		defaultCurrentTime.Unix(), "https://test.com",
+		defaultCurrentTime.Unix(), "http://example.com/feed", "title=Example",
+	)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := defaultCurrentTime.Add(-time.Hour)
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"http://example.com/feed": {
+			"item-1": {FirstSeen: seen, Read: true},
+			"item-2": {FirstSeen: defaultCurrentTime},
+		},
+		"https://www.example.com/feed/": {
+			"item-1": {FirstSeen: defaultCurrentTime},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveStarred([]*_storage.StarredItem{
+		{ID: "item-1", FeedURL: "http://example.com/feed", Title: "Item 1"},
+		{ID: "item-1", FeedURL: "https://www.example.com/feed/", Title: "Item 1"},
+		{ID: "item-2", FeedURL: "http://example.com/feed", Title: "Item 2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveFeedArchive("http://example.com/feed", []*gofeed.Item{{GUID: "item-0", Title: "Item 0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) error {
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err = run("duplicates")
+	assert.NoError(t, err)
+	assert.Equal(t, `https://www.example.com/feed/  (default)
+  http://example.com/feed  (default, tech)
+found 1 duplicate, merge them with: cleed duplicates --merge
+`, out.String())
+
+	err = run("duplicates", "--merge")
+	assert.NoError(t, err)
+	assert.Equal(t, `https://www.example.com/feed/  (default)
+  http://example.com/feed  (default, tech)
+merged 1 duplicate
+`, out.String())
+
+	b, err := os.ReadFile(path.Join(listsDir, "default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, fmt.Sprintf("%d %s\n%d %s\n",
+		defaultCurrentTime.Unix()-100, "https://www.example.com/feed/",
+		defaultCurrentTime.Unix(), "https://test.com",
+	), string(b))
+	b, err = os.ReadFile(path.Join(listsDir, "tech"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, fmt.Sprintf("%d %s\n%d %s %s\n",
+		defaultCurrentTime.Unix(), "https://test.com",
+		defaultCurrentTime.Unix(), "https://www.example.com/feed/", "title=Example",
+	), string(b))
+
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*_storage.ItemState{
+		"https://www.example.com/feed/": {
+			"item-1": {FirstSeen: seen, Read: true},
+			"item-2": {FirstSeen: defaultCurrentTime},
+		},
+	}, states)
+	starred, err := storage.LoadStarred()
+	assert.NoError(t, err)
+	assert.Equal(t, []*_storage.StarredItem{
+		{ID: "item-1", FeedURL: "https://www.example.com/feed/", Title: "Item 1"},
+		{ID: "item-2", FeedURL: "https://www.example.com/feed/", Title: "Item 2"},
+	}, starred)
+	archived, err := storage.LoadFeedArchive("https://www.example.com/feed/")
+	assert.NoError(t, err)
+	assert.Equal(t, []*gofeed.Item{{GUID: "item-0", Title: "Item 0"}}, archived)
+	archived, err = storage.LoadFeedArchive("http://example.com/feed")
+	assert.NoError(t, err)
+	assert.Empty(t, archived)
+
+	err = run("duplicates")
+	assert.NoError(t, err)
+	assert.Equal(t, "no duplicates found\n", out.String())
+}
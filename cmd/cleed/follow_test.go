@@ -58,6 +58,17 @@ func Test_Follow_Default(t *testing.T) {
 		defaultCurrentTime.Unix(), "https://example.com",
 		defaultCurrentTime.Unix(), "https://test.com",
 	), string(b))
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	// Feeds that are already in the list are not counted
+	os.Args = []string{"cleed", "follow", "http://www.example.com/", "https://other.com"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "added 1 feed to list: default\n", out.String())
 }
 
 func Test_Follow_Custom_List(t *testing.T) {
@@ -145,5 +156,49 @@ Flags:
   -h, --help          help for follow
   -L, --list string   the list to add the feed to (default "default")
 
+Global Flags:
+      --profile string   profile to use, each profile has its own lists, config and cache
+
 `, out.String())
 }
+
+func Test_Follow_Normalize_URL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "follow",
+		"HTTPS://Example.com:443/feed?utm_source=x&id=1#top",
+		"https://www.example.com/feed/?id=1",
+		"http://test.com:8080/rss",
+	}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path.Join(configDir, "cleed_test", "lists", "default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, fmt.Sprintf("%d %s\n%d %s\n",
+		defaultCurrentTime.Unix(), "https://example.com/feed?id=1",
+		defaultCurrentTime.Unix(), "http://test.com:8080/rss",
+	), string(b))
+}
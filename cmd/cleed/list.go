@@ -1,6 +1,7 @@
 package cleed
 
 import (
+	"github.com/radulucut/cleed/internal"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +18,13 @@ Examples:
   # Show all feeds in a list
   cleed list mylist
 
-  # Rename a list
+  # Lists can be nested using "/"
+  cleed follow https://example.com/feed.xml --list eng/backend
+
+  # Show all tags
+  cleed list --tags
+
+  # Rename a list and its nested lists
   cleed list mylist --rename newlist
 
   # Merge a list. Move all feeds from anotherlist to mylist and remove anotherlist
@@ -29,36 +36,92 @@ Examples:
   # Import feeds from a file
   cleed list mylist --import-from-file feeds.txt
 
-  # Import feeds from an OPML file
+  # Import feeds from an OPML file. Feeds from all categories are added to the list
   cleed list mylist --import-from-opml feeds.opml
 
+  # Import feeds from an OPML file, each category to its own nested list (e.g. mylist/tech)
+  cleed list mylist --import-from-opml feeds.opml --map-categories
+
+  # Import feeds from newsboat, a bookmarks file or a Miniflux/FreshRSS JSON export
+  cleed list mylist --import-from-newsboat ~/.newsboat/urls --map-categories
+  cleed list mylist --import-from-bookmarks bookmarks.html
+  cleed list mylist --import-from-json feeds.json
+
+  # Show which feeds are new, already followed, malformed or fail to fetch without importing them
+  cleed list mylist --import-from-opml feeds.opml --dry-run --validate
+
   # Export feeds to a file
   cleed list mylist --export-to-file feeds.txt
 
   # Export feeds to an OPML file
   cleed list mylist --export-to-opml feeds.opml
+
+  # Export all lists to an OPML file, each list as a category
+  cleed list --export-to-opml feeds.opml
+
+  # Use - to import from stdin or export to stdout
+  curl -s https://example.com/feeds.opml | cleed list mylist --import-from-opml -
+
+  # Keep a list in sync with a remote OPML. The OPML is cached and refreshed like a feed when feeds are fetched,
+  # and the titles and categories of its feeds are set as their title and tags
+  cleed list mylist --bind-opml https://example.com/feeds.opml
+
+  # Stop refreshing a list from a remote OPML
+  cleed list mylist --unbind-opml
+
+  # Set a custom title, a fixed color and a note for a feed. An empty value removes it
+  cleed list mylist --set https://example.com/feed.xml title="My feed" color=3 note="Weekly digest"
+
+  # Tag a feed. Tags are used to filter the feeds with --tag and --not-tag
+  cleed list mylist --set https://example.com/feed.xml tags=go,security
 `,
 
 		RunE: r.RunList,
-		Args: cobra.MaximumNArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flag("set").Changed {
+				return cobra.MinimumNArgs(2)(cmd, args)
+			}
+			return cobra.MaximumNArgs(1)(cmd, args)
+		},
 	}
 
 	flags := cmd.Flags()
 	flags.String("rename", "", "rename a list")
 	flags.String("merge", "", "merge a list")
 	flags.Bool("remove", false, "remove a list")
-	flags.String("import-from-file", "", "import feeds from a file. Newline separated URLs")
-	flags.String("import-from-opml", "", "import feeds from an OPML file")
-	flags.String("export-to-file", "", "export feeds to a file. Newline separated URLs")
-	flags.String("export-to-opml", "", "export feeds to an OPML file")
+	flags.String("import-from-file", "", "import feeds from a file (- for stdin). Newline separated URLs")
+	flags.String("import-from-opml", "", "import feeds from an OPML file (- for stdin)")
+	flags.String("import-from-newsboat", "", "import feeds from a newsboat urls file (- for stdin)")
+	flags.String("import-from-bookmarks", "", "import feeds from a Netscape bookmark HTML file (- for stdin)")
+	flags.String("import-from-json", "", "import feeds from a Miniflux or Google Reader API (e.g. FreshRSS) JSON export (- for stdin)")
+	flags.Bool("map-categories", false, "import each category (OPML folder, newsboat tag, bookmark folder or JSON category) to its own nested list")
+	flags.Bool("dry-run", false, "show the status of the feeds to import without adding them")
+	flags.Bool("validate", false, "fetch and parse the new feeds to import, feeds that fail are not added")
+	flags.String("export-to-file", "", "export feeds to a file (- for stdout). Newline separated URLs")
+	flags.String("export-to-opml", "", "export feeds to an OPML file (- for stdout)")
+	flags.String("bind-opml", "", "keep the list in sync with a remote OPML")
+	flags.Bool("unbind-opml", false, "stop syncing the list with a remote OPML")
+	flags.String("set", "", "set the metadata of a feed in the list (title, color, tags, site, note) using key=value arguments")
+	flags.Bool("tags", false, "show all tags")
 
 	r.Cmd.AddCommand(cmd)
 }
 
 func (r *Root) RunList(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
+		exportToOPML := cmd.Flag("export-to-opml").Value.String()
+		if exportToOPML != "" {
+			return r.feed.ExportToOPML(exportToOPML, "")
+		}
+		if cmd.Flag("tags").Changed {
+			return r.feed.Tags()
+		}
 		return r.feed.Lists()
 	}
+	set := cmd.Flag("set").Value.String()
+	if set != "" {
+		return r.feed.SetFeedMeta(args[0], set, args[1:])
+	}
 	rename := cmd.Flag("rename").Value.String()
 	if rename != "" {
 		return r.feed.RenameList(args[0], rename)
@@ -70,9 +133,14 @@ func (r *Root) RunList(cmd *cobra.Command, args []string) error {
 	if cmd.Flag("remove").Changed {
 		return r.feed.RemoveList(args[0])
 	}
+	importOpts := &internal.ImportOptions{
+		MapCategories: cmd.Flag("map-categories").Changed,
+		DryRun:        cmd.Flag("dry-run").Changed,
+		Validate:      cmd.Flag("validate").Changed,
+	}
 	importFromFile := cmd.Flag("import-from-file").Value.String()
 	if importFromFile != "" {
-		return r.feed.ImportFromFile(importFromFile, args[0])
+		return r.feed.ImportFromFile(importFromFile, args[0], importOpts)
 	}
 	exportToFile := cmd.Flag("export-to-file").Value.String()
 	if exportToFile != "" {
@@ -80,11 +148,30 @@ func (r *Root) RunList(cmd *cobra.Command, args []string) error {
 	}
 	importFromOPML := cmd.Flag("import-from-opml").Value.String()
 	if importFromOPML != "" {
-		return r.feed.ImportFromOPML(importFromOPML, args[0])
+		return r.feed.ImportFromOPML(importFromOPML, args[0], importOpts)
+	}
+	importFromNewsboat := cmd.Flag("import-from-newsboat").Value.String()
+	if importFromNewsboat != "" {
+		return r.feed.ImportFromNewsboat(importFromNewsboat, args[0], importOpts)
+	}
+	importFromBookmarks := cmd.Flag("import-from-bookmarks").Value.String()
+	if importFromBookmarks != "" {
+		return r.feed.ImportFromBookmarks(importFromBookmarks, args[0], importOpts)
+	}
+	importFromJSON := cmd.Flag("import-from-json").Value.String()
+	if importFromJSON != "" {
+		return r.feed.ImportFromJSON(importFromJSON, args[0], importOpts)
 	}
 	exportToOPML := cmd.Flag("export-to-opml").Value.String()
 	if exportToOPML != "" {
 		return r.feed.ExportToOPML(exportToOPML, args[0])
 	}
+	bindOPML := cmd.Flag("bind-opml").Value.String()
+	if bindOPML != "" {
+		return r.feed.BindRemoteOPML(args[0], bindOPML)
+	}
+	if cmd.Flag("unbind-opml").Changed {
+		return r.feed.UnbindRemoteOPML(args[0])
+	}
 	return r.feed.ListFeeds(args[0])
 }
@@ -3,6 +3,8 @@ package cleed
 import (
 	"bytes"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path"
@@ -398,13 +400,13 @@ func Test_List_Remove(t *testing.T) {
 
 	assert.NoFileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example2.com")))
 
-	b, err := os.ReadFile(path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
+	b, err := readFeedCacheFile(path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
 	if err != nil {
 		t.Fatal(err)
 	}
 	assert.Equal(t, "example", string(b))
 
-	b, err = os.ReadFile(path.Join(cacheDir, "feed_"+url.QueryEscape("https://test.com")))
+	b, err = readFeedCacheFile(path.Join(cacheDir, "feed_"+url.QueryEscape("https://test.com")))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -464,7 +466,7 @@ https://example2.com`), 0600)
 
 	err = root.Cmd.Execute()
 	assert.NoError(t, err)
-	assert.Equal(t, "added 3 feeds to list: test\n", out.String())
+	assert.Equal(t, "added 2 feeds to list: test\n", out.String())
 
 	items, err := storage.GetFeedsFromList("test")
 	assert.NoError(t, err)
@@ -657,15 +659,799 @@ func Test_List_ExportToOPML(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
-<opml version="1.0">
+<opml version="2.0">
   <head>
     <title>test</title>
   </head>
+  <body>
+    <outline text="test" title="test">
+      <outline text="https://example.com" type="rss" xmlUrl="https://example.com"></outline>
+      <outline text="https://test.com" type="rss" xmlUrl="https://test.com"></outline>
+    </outline>
+  </body>
+</opml>
+`, string(b))
+}
+
+func Test_List_RemoteOPML(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := defaultCurrentTime
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	var opml, etag string
+	opmlRequests := 0
+	rss := createDefaultRSS()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/opml" {
+			opmlRequests++
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Write([]byte(opml))
+		} else {
+			w.Write([]byte(rss))
+		}
+	}))
+	defer server.Close()
+
+	opml = fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+	<body>
+		<outline text="Team">
+			<outline text="Example" xmlUrl="%s/rss" category="News,Tech" />
+			<outline text="Nested">
+				<outline xmlUrl="%s/atom" />
+			</outline>
+		</outline>
+	</body>
+</opml>`, server.URL, server.URL)
+	etag = `"1"`
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("list", "team", "--bind-opml", server.URL+"/opml")
+	assert.NoError(t, err)
+	assert.Equal(t, "list team was bound to "+server.URL+"/opml\nlist team was updated: added 2 feeds, removed 0 feeds\n", out.String())
+	assert.Equal(t, []string{server.URL + "/rss", server.URL + "/atom"}, listAddresses(t, storage, "team"))
+	items, err := storage.GetFeedsFromList("team")
+	assert.NoError(t, err)
+	assert.Equal(t, "Example", items[0].Meta.Get(_storage.ListItemTitle))
+	assert.Equal(t, "news,tech", items[0].Meta.Get(_storage.ListItemTags))
+	assert.Nil(t, items[1].Meta)
+	assert.Equal(t, 1, opmlRequests)
+
+	opml = fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+	<body>
+		<outline text="Example News" xmlUrl="%s/rss" />
+		<outline xmlUrl="%s/rss2" />
+	</body>
+</opml>`, server.URL, server.URL)
+	etag = `"2"`
+
+	// The OPML is not fetched again before FetchAfter
+	err = run("--list", "team", "--limit", "1")
+	assert.NoError(t, err)
+	assert.NotContains(t, out.String(), "list team was updated")
+	assert.Equal(t, 1, opmlRequests)
+
+	now = now.Add(2 * time.Minute)
+	err = run("--list", "team", "--limit", "1")
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "list team was updated: added 1 feed, removed 1 feed\n")
+	assert.Equal(t, []string{server.URL + "/rss", server.URL + "/rss2"}, listAddresses(t, storage, "team"))
+	items, err = storage.GetFeedsFromList("team")
+	assert.NoError(t, err)
+	assert.Equal(t, "Example News", items[0].Meta.Get(_storage.ListItemTitle))
+	assert.Equal(t, "news,tech", items[0].Meta.Get(_storage.ListItemTags))
+	assert.Equal(t, 2, opmlRequests)
+
+	// An unchanged OPML is not updated again
+	now = now.Add(2 * time.Minute)
+	err = run("--list", "team", "--limit", "1")
+	assert.NoError(t, err)
+	assert.NotContains(t, out.String(), "list team was updated")
+	assert.Equal(t, 3, opmlRequests)
+
+	// The same feeds under other URLs are not added again
+	opml = fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+	<body>
+		<outline xmlUrl="%s/rss/" />
+		<outline xmlUrl="%s/rss2#feed" />
+	</body>
+</opml>`, server.URL, server.URL)
+	etag = `"3"`
+
+	now = now.Add(2 * time.Minute)
+	err = run("--list", "team", "--limit", "1")
+	assert.NoError(t, err)
+	assert.NotContains(t, out.String(), "list team was updated")
+	assert.Equal(t, []string{server.URL + "/rss", server.URL + "/rss2"}, listAddresses(t, storage, "team"))
+
+	opml = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+	<body></body>
+</opml>`
+	etag = `"4"`
+
+	now = now.Add(2 * time.Minute)
+	err = run("--list", "team", "--limit", "1")
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "failed to refresh list: team: failed to update list: remote OPML has no feeds, list team was kept\n")
+	assert.Equal(t, []string{server.URL + "/rss", server.URL + "/rss2"}, listAddresses(t, storage, "team"))
+
+	// A broken OPML is fetched again
+	now = now.Add(2 * time.Minute)
+	err = run("--list", "team", "--limit", "1")
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "failed to refresh list: team: failed to update list: remote OPML has no feeds, list team was kept\n")
+
+	// The cached OPML is kept when the caches are pruned
+	err = run("cache", "--prune")
+	assert.NoError(t, err)
+	cacheInfo, err := storage.LoadCacheInfo()
+	assert.NoError(t, err)
+	assert.NotNil(t, cacheInfo[server.URL+"/opml"])
+
+	err = run("list")
+	assert.NoError(t, err)
+	assert.Equal(t, "team (remote: "+server.URL+"/opml)\n", out.String())
+
+	err = run("list", "team", "--rename", "eng")
+	assert.NoError(t, err)
+	config, err := storage.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"eng": server.URL + "/opml"}, config.RemoteLists)
+
+	err = run("list", "eng", "--unbind-opml")
+	assert.NoError(t, err)
+	assert.Equal(t, "list eng was unbound\n", out.String())
+
+	err = run("list", "eng", "--unbind-opml")
+	assert.EqualError(t, err, "list is not bound to a remote OPML: eng")
+}
+
+func Test_List_SetMeta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("follow", "https://example.com", "https://test.com", "--list", "mylist")
+	assert.NoError(t, err)
+
+	err = run("list", "mylist", "--set", "https://example.com", "title=My feed", "color=3", "note=a&b")
+	assert.NoError(t, err)
+	assert.Equal(t, "updated https://example.com in list: mylist\n", out.String())
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path.Join(configDir, "cleed_test", "lists", "mylist"))
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d %s %s\n%d %s\n",
+		defaultCurrentTime.Unix(), "https://example.com", "color=3&note=a%26b&title=My+feed",
+		defaultCurrentTime.Unix(), "https://test.com",
+	), string(b))
+
+	err = run("list", "mylist")
+	assert.NoError(t, err)
+	assert.Equal(t, `2024-01-01 00:00:00  https://example.com  title="My feed" color=3 note=a&b
+2024-01-01 00:00:00  https://test.com
+Total: 2 feeds
+`, out.String())
+
+	err = run("list", "mylist", "--set", "https://example.com", "color=", "note=")
+	assert.NoError(t, err)
+	items, err := storage.GetFeedsFromList("mylist")
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"title": {"My feed"}}, items[0].Meta)
+
+	err = run("list", "mylist", "--set", "http://www.example.com/", "color=4")
+	assert.NoError(t, err)
+	assert.Equal(t, "updated http://www.example.com/ in list: mylist\n", out.String())
+	items, err = storage.GetFeedsFromList("mylist")
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"title": {"My feed"}, "color": {"4"}}, items[0].Meta)
+
+	err = run("list", "mylist", "--set", "https://example.com", "size=3")
+	assert.EqualError(t, err, "unknown metadata key: size")
+
+	err = run("list", "mylist", "--set", "https://example.com", "color=300")
+	assert.EqualError(t, err, "invalid color: 300, expected a value between 0 and 255")
+
+	err = run("list", "mylist", "--set", "https://missing.com", "title=Missing")
+	assert.EqualError(t, err, "feed https://missing.com was not found in list: mylist")
+}
+
+func Test_List_Tags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("follow", "https://example.com", "https://test.com", "--list", "mylist")
+	assert.NoError(t, err)
+
+	err = run("list", "mylist", "--set", "https://example.com", "tags=Go, security,go")
+	assert.NoError(t, err)
+
+	items, err := storage.GetFeedsFromList("mylist")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "security"}, items[0].Tags())
+	assert.Equal(t, []string{}, items[1].Tags())
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	importFilePath := path.Join(configDir, "cleed_test", "import.opml")
+	err = os.WriteFile(importFilePath,
+		[]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
   <body>
 	<outline text="test">
-      <outline xmlUrl="https://example.com"/>
-      <outline xmlUrl="https://test.com"/>
+      <outline xmlUrl="https://example1.com" category="/Go,/release-notes"/>
+      <outline xmlUrl="https://example2.com"/>
 	</outline>
   </body>
-</opml>`, string(b))
+</opml>`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = run("list", "other", "--import-from-opml", importFilePath)
+	assert.NoError(t, err)
+
+	err = run("list", "other")
+	assert.NoError(t, err)
+	assert.Equal(t, `2024-01-01 00:00:00  https://example1.com  tags=go,release-notes
+2024-01-01 00:00:00  https://example2.com
+Total: 2 feeds
+`, out.String())
+
+	err = run("list", "--tags")
+	assert.NoError(t, err)
+	assert.Equal(t, `go (2 feeds)
+release-notes (1 feed)
+security (1 feed)
+Total: 3 tags
+`, out.String())
+}
+
+func Test_List_Nested(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("follow", "https://backend.com", "--list", "eng/backend")
+	assert.NoError(t, err)
+	err = run("follow", "https://frontend.com", "--list", "eng/frontend/web")
+	assert.NoError(t, err)
+	err = run("follow", "https://example.com", "--list", "eng-other")
+	assert.NoError(t, err)
+	err = run("follow", "https://example.com", "--list", "a/../b")
+	assert.EqualError(t, err, "failed to save feeds: invalid list name: a/../b")
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = os.Stat(path.Join(configDir, "cleed_test", "lists", "eng%2Fbackend"))
+	assert.NoError(t, err)
+
+	err = run("list")
+	assert.NoError(t, err)
+	assert.Equal(t, `eng
+  backend
+  frontend
+    web
+eng-other
+`, out.String())
+
+	err = run("list", "eng", "--rename", "team")
+	assert.NoError(t, err)
+	assert.Equal(t, "list eng was renamed to team\n", out.String())
+
+	err = run("list")
+	assert.NoError(t, err)
+	assert.Equal(t, `eng-other
+team
+  backend
+  frontend
+    web
+`, out.String())
+
+	err = run("list", "team", "--rename", "team/sub")
+	assert.EqualError(t, err, "failed to rename list: cannot rename list team to team/sub")
+
+	exportPath := path.Join(configDir, "cleed_test", "export.opml")
+	err = run("list", "team", "--export-to-opml", exportPath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("exported 2 feeds to %s\n", exportPath), out.String())
+
+	b, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>team</title>
+  </head>
+  <body>
+    <outline text="team" title="team">
+      <outline text="backend" title="backend">
+        <outline text="https://backend.com" type="rss" xmlUrl="https://backend.com"></outline>
+      </outline>
+      <outline text="frontend" title="frontend">
+        <outline text="web" title="web">
+          <outline text="https://frontend.com" type="rss" xmlUrl="https://frontend.com"></outline>
+        </outline>
+      </outline>
+    </outline>
+  </body>
+</opml>
+`, string(b))
+
+	err = run("list", "imported", "--import-from-opml", exportPath, "--map-categories")
+	assert.NoError(t, err)
+	assert.Equal(t, `added 1 feed to list: imported/backend
+added 1 feed to list: imported/frontend/web
+`, out.String())
+
+	items, err := storage.GetFeedsFromList("imported/frontend/web")
+	assert.NoError(t, err)
+	assert.Equal(t, []*_storage.ListItem{
+		{AddedAt: time.Unix(defaultCurrentTime.Unix(), 0), Address: "https://frontend.com"},
+	}, items)
+}
+
+func Test_List_ImportFromOPML_Recursive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(path.Join(configDir, "cleed_test"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	importFilePath := path.Join(configDir, "cleed_test", "import.opml")
+	err = os.WriteFile(importFilePath,
+		[]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <body>
+    <outline text="Example" title="Example Feed" type="rss" xmlUrl="https://example.com/feed" htmlUrl="https://example.com"/>
+    <outline text="Tech">
+      <outline text="Go" xmlUrl="https://go.dev/blog/feed.atom"/>
+      <outline text="Security/News">
+        <outline text="Advisories" xmlUrl="https://security.com/feed"/>
+      </outline>
+    </outline>
+    <outline text="Empty"/>
+  </body>
+</opml>`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = run("list", "flat", "--import-from-opml", importFilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "added 3 feeds to list: flat\n", out.String())
+
+	err = run("list", "flat")
+	assert.NoError(t, err)
+	assert.Equal(t, `2024-01-01 00:00:00  https://example.com/feed  title="Example Feed" site=https://example.com
+2024-01-01 00:00:00  https://go.dev/blog/feed.atom  title=Go
+2024-01-01 00:00:00  https://security.com/feed  title=Advisories
+Total: 3 feeds
+`, out.String())
+
+	err = run("list", "mapped", "--import-from-opml", importFilePath, "--map-categories")
+	assert.NoError(t, err)
+	assert.Equal(t, `added 1 feed to list: mapped
+added 1 feed to list: mapped/Tech
+added 1 feed to list: mapped/Tech/Security-News
+`, out.String())
+
+	err = run("list")
+	assert.NoError(t, err)
+	assert.Equal(t, `flat
+mapped
+  Tech
+    Security-News
+`, out.String())
+
+	err = os.WriteFile(importFilePath, []byte(`<opml version="2.0"><body><outline text="Empty"/></body></opml>`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = run("list", "flat", "--import-from-opml", importFilePath)
+	assert.EqualError(t, err, "no feeds found in OPML")
+}
+
+func Test_List_ExportToOPML_All(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("follow", "https://example.com/feed?a=1&b=2", "https://test.com", "--list", "default")
+	assert.NoError(t, err)
+	err = run("follow", "https://backend.com", "--list", "eng/backend")
+	assert.NoError(t, err)
+	err = run("list", "default", "--set", "https://example.com/feed?a=1&b=2", "title=Tom & Jerry", "site=https://example.com", "tags=go,security")
+	assert.NoError(t, err)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exportPath := path.Join(configDir, "cleed_test", "export.opml")
+	err = run("list", "--export-to-opml", exportPath)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("exported 3 feeds to %s\n", exportPath), out.String())
+
+	b, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>cleed</title>
+  </head>
+  <body>
+    <outline text="default" title="default">
+      <outline text="Tom &amp; Jerry" title="Tom &amp; Jerry" type="rss" xmlUrl="https://example.com/feed?a=1&amp;b=2" htmlUrl="https://example.com" category="go,security"></outline>
+      <outline text="https://test.com" type="rss" xmlUrl="https://test.com"></outline>
+    </outline>
+    <outline text="eng" title="eng">
+      <outline text="backend" title="backend">
+        <outline text="https://backend.com" type="rss" xmlUrl="https://backend.com"></outline>
+      </outline>
+    </outline>
+  </body>
+</opml>
+`, string(b))
+
+	err = run("list", "imported", "--import-from-opml", exportPath, "--map-categories")
+	assert.NoError(t, err)
+
+	for _, lists := range [][2]string{
+		{"default", "imported/default"},
+		{"eng/backend", "imported/eng/backend"},
+	} {
+		exported, err := storage.GetFeedsFromList(lists[0])
+		assert.NoError(t, err)
+		imported, err := storage.GetFeedsFromList(lists[1])
+		assert.NoError(t, err)
+		assert.Equal(t, exported, imported)
+	}
+}
+
+func Test_List_ImportExport_Stdio(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	in := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	printer := internal.NewPrinter(in, out, errOut)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(input string, args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		in.Reset()
+		in.WriteString(input)
+		out.Reset()
+		errOut.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("https://example.com\n# comment\nhttps://test.com\n", "list", "mylist", "--import-from-file", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "added 2 feeds to list: mylist\n", out.String())
+
+	err = run(`<opml version="2.0"><body><outline text="Other" xmlUrl="https://other.com"/></body></opml>`, "list", "mylist", "--import-from-opml", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "added 1 feed to list: mylist\n", out.String())
+
+	err = run("", "list", "mylist", "--export-to-file", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com\nhttps://test.com\nhttps://other.com\n", out.String())
+	assert.Equal(t, "exported 3 feeds to stdout\n", errOut.String())
+
+	err = run("", "list", "mylist", "--export-to-opml", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>mylist</title>
+  </head>
+  <body>
+    <outline text="mylist" title="mylist">
+      <outline text="https://example.com" type="rss" xmlUrl="https://example.com"></outline>
+      <outline text="https://test.com" type="rss" xmlUrl="https://test.com"></outline>
+      <outline text="Other" title="Other" type="rss" xmlUrl="https://other.com"></outline>
+    </outline>
+  </body>
+</opml>
+`, out.String())
+	assert.Equal(t, "exported 3 feeds to stdout\n", errOut.String())
+
+	err = run("", "list", "empty", "--export-to-file", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "no feeds to export\n", errOut.String())
+}
+
+func Test_List_ImportFromOtherReaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	in := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(in, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(input string, args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		in.Reset()
+		in.WriteString(input)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run(`https://example.com/feed go security "~Example"
+https://test.com/feed
+`, "list", "newsboat", "--import-from-newsboat", "-", "--map-categories")
+	assert.NoError(t, err)
+	assert.Equal(t, `added 1 feed to list: newsboat/go
+added 1 feed to list: newsboat
+`, out.String())
+
+	err = run("", "list", "newsboat/go")
+	assert.NoError(t, err)
+	assert.Equal(t, `2024-01-01 00:00:00  https://example.com/feed  title=Example tags=go,security
+Total: 1 feed
+`, out.String())
+
+	err = run(`<DL><p><DT><H3>News</H3><DL><p><DT><A HREF="https://news.com/rss">News</A></DL><p></DL>`,
+		"list", "bookmarks", "--import-from-bookmarks", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "added 1 feed to list: bookmarks\n", out.String())
+
+	err = run(`[{"feed_url": "https://miniflux.com/feed", "title": "Miniflux", "category": {"title": "All"}}]`,
+		"list", "json", "--import-from-json", "-", "--map-categories")
+	assert.NoError(t, err)
+	assert.Equal(t, "added 1 feed to list: json/All\n", out.String())
+
+	err = run("# no feeds\n", "list", "newsboat", "--import-from-newsboat", "-")
+	assert.EqualError(t, err, "no feeds found in newsboat urls file")
+}
+
+func Test_List_Import_DryRun_Validate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	in := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(in, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	rss := createDefaultRSS()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss":
+			w.Write([]byte(rss))
+		case "/html":
+			w.Write([]byte("<html></html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(input string, args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		in.Reset()
+		in.WriteString(input)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("", "follow", server.URL+"/followed", "--list", "default")
+	assert.NoError(t, err)
+	err = run("", "follow", server.URL+"/followed", "--list", "other")
+	assert.NoError(t, err)
+
+	input := fmt.Sprintf("%[1]s/rss\n%[1]s/followed\n%[1]s/rss\nnot a url\n%[1]s/missing\n%[1]s/html\n", server.URL)
+
+	err = run(input, "list", "mylist", "--import-from-file", "-", "--dry-run")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`new        %[1]s/rss
+followed   %[1]s/followed  (default, other)
+duplicate  %[1]s/rss
+malformed  not a url
+new        %[1]s/missing
+new        %[1]s/html
+3 new, 1 followed, 1 duplicate, 1 malformed, 0 failed
+dry run, no feeds were added
+`, server.URL), out.String())
+
+	lists, err := storage.LoadLists()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"default", "other"}, lists)
+
+	err = run(input, "list", "mylist", "--import-from-file", "-", "--validate")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`new        %[1]s/rss
+followed   %[1]s/followed  (default, other)
+duplicate  %[1]s/rss
+malformed  not a url
+failed     %[1]s/missing  (unexpected status code: 404)
+failed     %[1]s/html  (Failed to detect feed type)
+1 new, 1 followed, 1 duplicate, 1 malformed, 2 failed
+added 2 feeds to list: mylist
+`, server.URL), out.String())
+
+	items, err := storage.GetFeedsFromList("mylist")
+	assert.NoError(t, err)
+	assert.Equal(t, []*_storage.ListItem{
+		{AddedAt: time.Unix(defaultCurrentTime.Unix(), 0), Address: server.URL + "/rss"},
+		{AddedAt: time.Unix(defaultCurrentTime.Unix(), 0), Address: server.URL + "/followed"},
+	}, items)
+
+	err = run(fmt.Sprintf("not a url\n%s/rss\n", server.URL), "list", "plain", "--import-from-file", "-")
+	assert.NoError(t, err)
+	assert.Equal(t, "skipped invalid feed URL: not a url\nadded 1 feed to list: plain\n", out.String())
+
+	items, err = storage.GetFeedsFromList("plain")
+	assert.NoError(t, err)
+	assert.Equal(t, []*_storage.ListItem{
+		{AddedAt: time.Unix(defaultCurrentTime.Unix(), 0), Address: server.URL + "/rss"},
+	}, items)
+
+	err = run("not a url\n", "list", "plain", "--import-from-file", "-")
+	assert.EqualError(t, err, "no valid feeds found in file")
 }
@@ -0,0 +1,47 @@
+package cleed
+
+import (
+	"github.com/radulucut/cleed/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func (r *Root) initRead() {
+	cmd := &cobra.Command{
+		Use:   "read [item]",
+		Short: "Mark items as read",
+		Long: `Mark items as read
+
+Examples:
+  # Mark an item as read using its link or GUID
+  cleed read https://example.com/item-1
+
+  # Mark all items in a list as read
+  cleed read --list mylist
+
+  # Mark all items as read
+  cleed read --all
+`,
+		RunE: r.RunRead,
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("list", "L", "", "the list to mark items in")
+	flags.Bool("all", false, "mark all items")
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunRead(cmd *cobra.Command, args []string) error {
+	return r.runMarkRead(cmd, args, true)
+}
+
+func (r *Root) runMarkRead(cmd *cobra.Command, args []string, read bool) error {
+	list, err := cmd.Flags().GetString("list")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 && list == "" && !cmd.Flag("all").Changed {
+		return utils.NewInternalError("please provide at least one item, a list or --all")
+	}
+	return r.feed.MarkRead(args, list, read)
+}
@@ -0,0 +1,183 @@
+package cleed
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Read_Items(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n%d %s\n",
+			defaultCurrentTime.Unix(), "https://example.com",
+			defaultCurrentTime.Unix(), "https://test.com",
+		),
+		), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"guid-1": {Link: "https://example.com/item-1", FirstSeen: defaultCurrentTime},
+			"guid-2": {Link: "https://example.com/item-2", FirstSeen: defaultCurrentTime},
+		},
+		"https://test.com": {
+			"https://test.com/item-1": {Link: "https://test.com/item-1", FirstSeen: defaultCurrentTime},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "read", "guid-1", "https://test.com/item-1", "https://not-found.com"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `guid-1 was marked as read
+https://test.com/item-1 was marked as read
+https://not-found.com was not found
+`, out.String())
+
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"guid-1": {Link: "https://example.com/item-1", FirstSeen: defaultCurrentTime, Read: true},
+			"guid-2": {Link: "https://example.com/item-2", FirstSeen: defaultCurrentTime},
+		},
+		"https://test.com": {
+			"https://test.com/item-1": {Link: "https://test.com/item-1", FirstSeen: defaultCurrentTime, Read: true},
+		},
+	}, states)
+}
+
+func Test_Read_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://example.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "test"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://test.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"guid-1": {Link: "https://example.com/item-1", FirstSeen: defaultCurrentTime},
+			"guid-2": {Link: "https://example.com/item-2", FirstSeen: defaultCurrentTime},
+		},
+		"https://test.com": {
+			"guid-1": {Link: "https://test.com/item-1", FirstSeen: defaultCurrentTime},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "read", "--list", "default"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "marked all items in list default as read\n", out.String())
+
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"guid-1": {Link: "https://example.com/item-1", FirstSeen: defaultCurrentTime, Read: true},
+			"guid-2": {Link: "https://example.com/item-2", FirstSeen: defaultCurrentTime, Read: true},
+		},
+		"https://test.com": {
+			"guid-1": {Link: "https://test.com/item-1", FirstSeen: defaultCurrentTime},
+		},
+	}, states)
+}
+
+func Test_Read_NoArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "read"}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "please provide at least one item, a list or --all")
+}
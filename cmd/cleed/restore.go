@@ -0,0 +1,24 @@
+package cleed
+
+import "github.com/spf13/cobra"
+
+func (r *Root) initRestore() {
+	cmd := &cobra.Command{
+		Use:   "restore [file]",
+		Short: "Restore a backup",
+		Long: `Restore a backup created with cleed backup. The current config, lists and item state are replaced
+
+Examples:
+  # Restore from a file
+  cleed restore cleed.tar.gz
+`,
+		RunE: r.RunRestore,
+		Args: cobra.ExactArgs(1),
+	}
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunRestore(cmd *cobra.Command, args []string) error {
+	return r.feed.Restore(args[0])
+}
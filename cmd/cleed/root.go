@@ -3,6 +3,8 @@ package cleed
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/radulucut/cleed/internal"
@@ -40,6 +42,8 @@ type Root struct {
 	printer *internal.Printer
 	storage *storage.LocalStorage
 	feed    *internal.TerminalFeed
+
+	styling bool
 }
 
 func NewRoot(
@@ -55,18 +59,13 @@ func NewRoot(
 		printer: printer,
 		storage: storage,
 		feed:    feed,
+		styling: printer.GetStyling(),
 	}
-	err := root.storage.Init(root.version)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize storage: %v", err)
-	}
-
-	config, err := root.storage.LoadConfig()
+	root.storage.SetConfigDir(os.Getenv("CLEED_CONFIG_DIR"))
+	root.storage.SetCacheDir(os.Getenv("CLEED_CACHE_DIR"))
+	err := root.storage.SetProfile(os.Getenv("CLEED_PROFILE"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %v", err)
-	}
-	if config.Styling != 0 {
-		root.printer.SetStyling(config.Styling == 1)
+		return nil, err
 	}
 
 	root.Cmd = &cobra.Command{
@@ -81,6 +80,9 @@ Examples:
   # Display feeds from a specific list
   cleed --list my-list
 
+  # Display feeds from a list and all its nested lists (e.g. eng/backend, eng/frontend)
+  cleed --list eng
+
   # Display feeds since a specific date
   cleed --since "2024-01-01 12:03:04"
 
@@ -95,19 +97,54 @@ Examples:
 
   # Search for items
   cleed --search "keyword" --limit 10
+
+  # Display only unread items
+  cleed --unread
+
+  # Display items from feeds tagged with go or security, except release notes
+  cleed --tag go,security --not-tag release-notes
+
+  # Display starred items
+  cleed --starred
+
+  # Print items as JSON, newline-delimited JSON, CSV or TSV
+  cleed --output json | jq '.[].title'
+  cleed --unread --output csv > items.csv
+
+  # Print a single Atom, RSS or JSON Feed with the items of all feeds, e.g. to publish it
+  cleed --list eng --output atom > eng.xml
+  cleed --list eng --output atom --feed-url https://example.com/eng.xml > eng.xml
+
+  # Display items using a built-in template (compact, markdown, org), a template file or an inline template
+  cleed --template compact
+  cleed --template ~/.config/cleed/item.tmpl
+  cleed --template '{{.Feed.Title}}: {{.Item.Title}} {{.Item.Link}}'
+
+  # Use the work profile
+  cleed --profile work
 `,
-		Version: version,
-		RunE:    root.RunRoot,
+		Version:           version,
+		PersistentPreRunE: root.RunPersistentPre,
+		RunE:              root.RunRoot,
 	}
 
 	root.Cmd.SetOut(root.printer.OutWriter)
 	root.Cmd.SetErr(root.printer.ErrWriter)
 
+	root.Cmd.PersistentFlags().String("profile", "", "profile to use, each profile has its own lists, config and cache")
+
 	flags := root.Cmd.Flags()
 	flags.StringP("list", "L", "", "list to display feeds from")
 	flags.Uint("limit", 50, "limit the number of items to display")
 	flags.String("since", "", "display feeds since the last run (last), a specific date (e.g. 2024-01-01 12:03:04) or duration (e.g. 1d)")
 	flags.String("search", "", "search for items (title, categories)")
+	flags.Bool("unread", false, "display only unread items")
+	flags.StringSlice("tag", nil, "display items from feeds with any of the tags")
+	flags.StringSlice("not-tag", nil, "exclude items from feeds with any of the tags")
+	flags.Bool("starred", false, "display starred items")
+	flags.StringP("output", "o", "", "print items as structured records (json, ndjson, csv, tsv) or as a single feed (atom, rss, jsonfeed)")
+	flags.String("feed-url", "", "URL the feed printed with --output atom, rss or jsonfeed is published at")
+	flags.StringP("template", "t", "", "display items using a template: "+strings.Join(internal.BuiltinTemplateNames(), ", ")+", a file or an inline template")
 	flags.Bool("config-path", false, "show the path to the config directory")
 	flags.Bool("cache-path", false, "show the path to the cache directory")
 	flags.Bool("cache-info", false, "show the cache information")
@@ -117,10 +154,49 @@ Examples:
 	root.initUnfollow()
 	root.initList()
 	root.initConfig()
+	root.initRead()
+	root.initUnread()
+	root.initStar()
+	root.initUnstar()
+	root.initCache()
+	root.initDuplicates()
+	root.initBackup()
+	root.initRestore()
+	root.initSync()
+	root.initTUI()
 
 	return root, nil
 }
 
+// RunPersistentPre initializes the storage of the selected profile once the
+// flags are parsed, so that --profile takes precedence over CLEED_PROFILE.
+func (r *Root) RunPersistentPre(cmd *cobra.Command, args []string) error {
+	if cmd.Flag("profile").Changed {
+		err := r.storage.SetProfile(cmd.Flag("profile").Value.String())
+		if err != nil {
+			return err
+		}
+	}
+	return r.initStorage()
+}
+
+func (r *Root) initStorage() error {
+	err := r.storage.Init(r.version)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %v", err)
+	}
+	config, err := r.storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+	if config.Styling != 0 {
+		r.printer.SetStyling(config.Styling == 1)
+	} else {
+		r.printer.SetStyling(r.styling)
+	}
+	return nil
+}
+
 func (r *Root) RunRoot(cmd *cobra.Command, args []string) error {
 	if cmd.Flag("config-path").Changed {
 		return r.feed.ShowConfigPath()
@@ -139,10 +215,52 @@ func (r *Root) RunRoot(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	tags, err := cmd.Flags().GetStringSlice("tag")
+	if err != nil {
+		return err
+	}
+	notTags, err := cmd.Flags().GetStringSlice("not-tag")
+	if err != nil {
+		return err
+	}
+	output := cmd.Flag("output").Value.String()
+	if output != "" && !slices.Contains(internal.OutputFormats, output) {
+		return utils.NewInternalError("invalid output format: " + output + ", must be one of: " + strings.Join(internal.OutputFormats, ", "))
+	}
+	feedURL := cmd.Flag("feed-url").Value.String()
+	if feedURL != "" && output != internal.OutputAtom && output != internal.OutputRSS && output != internal.OutputJSONFeed {
+		return utils.NewInternalError("--feed-url can only be used with --output atom, rss or jsonfeed")
+	}
+	tmpl := cmd.Flag("template").Value.String()
+	if cmd.Flag("template").Changed {
+		if output != "" {
+			return utils.NewInternalError("--template cannot be used with --output")
+		}
+	} else if output == "" {
+		config, err := r.storage.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %v", err)
+		}
+		tmpl = config.Template
+	}
 	opts := &internal.FeedOptions{
-		List:  cmd.Flag("list").Value.String(),
-		Limit: int(limit),
-		Since: since,
+		List:    cmd.Flag("list").Value.String(),
+		Limit:   int(limit),
+		Since:   since,
+		Unread:  cmd.Flag("unread").Changed,
+		Tags:    tags,
+		NotTags: notTags,
+		Output:  output,
+		FeedURL: feedURL,
+	}
+	if tmpl != "" {
+		opts.Template, err = r.feed.ParseTemplate(tmpl)
+		if err != nil {
+			return utils.NewInternalError("failed to parse template: " + err.Error())
+		}
+	}
+	if cmd.Flag("starred").Changed {
+		return r.feed.Starred(cmd.Flag("search").Value.String(), opts)
 	}
 	if cmd.Flag("search").Changed {
 		return r.feed.Search(cmd.Flag("search").Value.String(), opts)
@@ -9,9 +9,11 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mmcdole/gofeed"
 	"github.com/radulucut/cleed/internal"
 	_storage "github.com/radulucut/cleed/internal/storage"
 	"github.com/radulucut/cleed/mocks"
@@ -103,7 +105,16 @@ RSS Feed        • Item 1
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert.Len(t, files, 3)
+	assert.Len(t, files, 5)
+	archiveDir, err := storage.JoinConfigDir("archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err = os.ReadDir(archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, files, 2)
 
 	cacheInfo, err := storage.LoadCacheInfo()
 	assert.NoError(t, err)
@@ -121,13 +132,13 @@ RSS Feed        • Item 1
 		FetchAfter: time.Unix(defaultCurrentTime.Unix()+60, 0),
 	}, cacheInfo[server.URL+"/atom"])
 
-	b, err := os.ReadFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/rss")))
+	b, err := readFeedCacheFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/rss")))
 	if err != nil {
 		t.Fatal(err)
 	}
 	assert.Equal(t, rss, string(b))
 
-	b, err = os.ReadFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/atom")))
+	b, err = readFeedCacheFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/atom")))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -315,7 +326,16 @@ Displayed 4 items from 2 feeds (0 cached, 2 fetched) with 4 items in 0.00s
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert.Len(t, files, 3)
+	assert.Len(t, files, 5)
+	archiveDir, err := storage.JoinConfigDir("archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err = os.ReadDir(archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, files, 2)
 
 	cacheInfo, err := storage.LoadCacheInfo()
 	assert.NoError(t, err)
@@ -333,13 +353,13 @@ Displayed 4 items from 2 feeds (0 cached, 2 fetched) with 4 items in 0.00s
 		FetchAfter: time.Unix(defaultCurrentTime.Unix()+60, 0),
 	}, cacheInfo[server.URL+"/atom"])
 
-	b, err := os.ReadFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/rss")))
+	b, err := readFeedCacheFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/rss")))
 	if err != nil {
 		t.Fatal(err)
 	}
 	assert.Equal(t, rss, string(b))
 
-	b, err = os.ReadFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/atom")))
+	b, err = readFeedCacheFile(path.Join(cacheDir, "feed_"+url.QueryEscape(server.URL+"/atom")))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -410,6 +430,76 @@ Atom Feed      • Item 1
 `, out.String())
 }
 
+func Test_Feed_Nested_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+	storage.Init("0.1.0")
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := createDefaultRSS()
+	atom := createDefaultAtom()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/atom" {
+			w.Write([]byte(atom))
+			return
+		}
+		w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(path.Join(listsDir, "eng%2Fbackend"),
+		[]byte(fmt.Sprintf("%d %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/atom",
+		),
+		), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "engineering"),
+		[]byte(fmt.Sprintf("%d %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/rss",
+		),
+		), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"cleed", "--list", "eng"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `Atom Feed      • Item 2
+1594 days ago  https://atom-feed.com/item-2/
+
+Atom Feed      • Item 1
+18 hours ago   https://atom-feed.com/item-1/
+
+`, out.String())
+}
+
 func Test_Feed_NotModified(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -681,6 +771,15 @@ func Test_Feed_FetchAfter_Load_From_Cache(t *testing.T) {
 			FetchAfter: time.Unix(defaultCurrentTime.Unix()+300, 0),
 		},
 	})
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"https://rss-feed.com/item-1/": {Link: "https://rss-feed.com/item-1/", FirstSeen: defaultCurrentTime.Add(-time.Hour)},
+			"https://rss-feed.com/item-2/": {Link: "https://rss-feed.com/item-2/", FirstSeen: defaultCurrentTime.Add(-time.Hour)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	feed := internal.NewTerminalFeed(timeMock, printer, storage)
 	feed.SetAgent("cleed/test")
@@ -1106,7 +1205,7 @@ Displayed 1 item from 2 feeds (2 cached, 0 fetched) with 4 items in 0.00s
 	assert.Equal(t, defaultCurrentTime, config.LastRun)
 }
 
-func Test_Config_Dir(t *testing.T) {
+func Test_Feed_Unread(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -1122,6 +1221,50 @@ func Test_Config_Dir(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://example.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir = path.Join(cacheDir, "cleed_test")
+	err = os.MkdirAll(cacheDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveFeedCache(bytes.NewBufferString(createDefaultRSS()), "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveCacheInfo(map[string]*_storage.CacheInfoItem{
+		"https://example.com": {
+			URL:        "https://example.com",
+			LastFetch:  time.Unix(defaultCurrentTime.Unix(), 0),
+			FetchAfter: time.Unix(defaultCurrentTime.Unix()+300, 0),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"https://rss-feed.com/item-1/": {Link: "https://rss-feed.com/item-1/", FirstSeen: defaultCurrentTime.Add(-time.Hour), Read: true},
+			"https://rss-feed.com/item-3/": {Link: "https://rss-feed.com/item-3/", FirstSeen: defaultCurrentTime.Add(-time.Hour), Read: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	feed := internal.NewTerminalFeed(timeMock, printer, storage)
 	feed.SetAgent("cleed/test")
@@ -1129,14 +1272,26 @@ func Test_Config_Dir(t *testing.T) {
 	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
 	assert.NoError(t, err)
 
-	os.Args = []string{"cleed", "--config-path"}
+	os.Args = []string{"cleed", "--unread"}
 
 	err = root.Cmd.Execute()
 	assert.NoError(t, err)
-	assert.Equal(t, path.Join(configDir, "cleed_test")+"\n", out.String())
+	assert.Equal(t, `RSS Feed       • Item 2
+1688 days ago  https://rss-feed.com/item-2/
+
+`, out.String())
+
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"https://rss-feed.com/item-1/": {Link: "https://rss-feed.com/item-1/", FirstSeen: defaultCurrentTime.Add(-time.Hour), Read: true},
+			"https://rss-feed.com/item-2/": {Link: "https://rss-feed.com/item-2/", FirstSeen: defaultCurrentTime},
+		},
+	}, states)
 }
 
-func Test_Cache_Dir(t *testing.T) {
+func Test_Feed_Starred(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -1148,7 +1303,30 @@ func Test_Cache_Dir(t *testing.T) {
 	storage := _storage.NewLocalStorage("cleed_test", timeMock)
 	defer localStorageCleanup(t, storage)
 
-	cacheDir, err := os.UserCacheDir()
+	storage.Init("0.1.0")
+
+	published1 := defaultCurrentTime.Add(-2 * time.Hour)
+	published2 := defaultCurrentTime.Add(-48 * time.Hour)
+	err := storage.SaveStarred([]*_storage.StarredItem{
+		{
+			ID:        "guid-2",
+			FeedURL:   "https://test.com",
+			FeedTitle: "Test Feed",
+			Title:     "Item 2",
+			Link:      "https://test.com/item-2",
+			Published: &published2,
+			StarredAt: defaultCurrentTime,
+		},
+		{
+			ID:        "guid-1",
+			FeedURL:   "https://example.com",
+			FeedTitle: "Example Feed",
+			Title:     "Item 1",
+			Link:      "https://example.com/item-1",
+			Published: &published1,
+			StarredAt: defaultCurrentTime,
+		},
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1159,14 +1337,20 @@ func Test_Cache_Dir(t *testing.T) {
 	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
 	assert.NoError(t, err)
 
-	os.Args = []string{"cleed", "--cache-path"}
+	os.Args = []string{"cleed", "--starred"}
 
 	err = root.Cmd.Execute()
 	assert.NoError(t, err)
-	assert.Equal(t, path.Join(cacheDir, "cleed_test")+"\n", out.String())
+	assert.Equal(t, `Test Feed     Item 2
+2 days ago    https://test.com/item-2
+
+Example Feed  Item 1
+2 hours ago   https://example.com/item-1
+
+`, out.String())
 }
 
-func Test_Cache_Info(t *testing.T) {
+func Test_Feed_Starred_Filters(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -1180,21 +1364,134 @@ func Test_Cache_Info(t *testing.T) {
 
 	storage.Init("0.1.0")
 
-	cacheInfo := map[string]*_storage.CacheInfoItem{
-		"https://example.com/rss": {
-			URL:        "https://example.com/rss",
-			LastFetch:  time.Unix(defaultCurrentTime.Unix(), 0),
-			ETag:       "etag",
-			FetchAfter: time.Unix(defaultCurrentTime.Unix()+300, 0),
-		},
-		"https://example.com/atom": {
-			URL:        "https://example.com/atom",
-			LastFetch:  time.Unix(defaultCurrentTime.Unix(), 0),
-			ETag:       "",
-			FetchAfter: time.Unix(defaultCurrentTime.Unix()+300, 0),
-		},
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
 	}
-	err := storage.SaveCacheInfo(cacheInfo)
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s %s\n", defaultCurrentTime.Unix(), "https://example.com", "title=Example&tags=go")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "tech"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://test.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published1 := defaultCurrentTime.Add(-2 * time.Hour)
+	published2 := defaultCurrentTime.Add(-48 * time.Hour)
+	published3 := defaultCurrentTime.Add(-72 * time.Hour)
+	err = storage.SaveStarred([]*_storage.StarredItem{
+		{ID: "guid-1", FeedURL: "https://example.com", FeedTitle: "Example Feed", Title: "Item 1", Link: "https://example.com/item-1", Published: &published1},
+		{ID: "guid-2", FeedURL: "https://test.com", FeedTitle: "Test Feed", Title: "Item 2", Link: "https://test.com/item-2", Published: &published2},
+		{ID: "guid-3", FeedURL: "https://unfollowed.com", FeedTitle: "Unfollowed Feed", Title: "Release notes", Link: "https://unfollowed.com/item-3", Published: &published3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {"guid-1": {FirstSeen: defaultCurrentTime, Read: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed", "--starred"}, args...)
+		err = root.Cmd.Execute()
+		assert.NoError(t, err)
+	}
+
+	run("--list", "default")
+	assert.Equal(t, `Example      Item 1
+2 hours ago  https://example.com/item-1
+
+`, out.String())
+
+	run("--tag", "go")
+	assert.Equal(t, `Example      Item 1
+2 hours ago  https://example.com/item-1
+
+`, out.String())
+
+	run("--unread")
+	assert.Equal(t, `Unfollowed Feed  Release notes
+3 days ago       https://unfollowed.com/item-3
+
+Test Feed        Item 2
+2 days ago       https://test.com/item-2
+
+`, out.String())
+
+	run("--search", "release")
+	assert.Equal(t, `Unfollowed Feed  Release notes
+3 days ago       https://unfollowed.com/item-3
+
+`, out.String())
+}
+
+func Test_Feed_Archive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(createRSS([]*FeedItem{
+			{
+				Title:     "Item 3",
+				Link:      "https://rss-feed.com/item-3/",
+				Published: "Sun, 31 Dec 2023 12:00:00 GMT",
+			},
+		})))
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), server.URL)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir = path.Join(cacheDir, "cleed_test")
+	err = os.MkdirAll(cacheDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	published1 := time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC)
+	published2 := time.Date(2023, 10, 15, 0, 0, 0, 0, time.UTC)
+	err = storage.SaveFeedArchive(server.URL, []*gofeed.Item{
+		{Title: "Item 1", Link: "https://rss-feed.com/item-1/", PublishedParsed: &published1},
+		{Title: "Item 2", Link: "https://rss-feed.com/item-2/", PublishedParsed: &published2},
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1205,17 +1502,1072 @@ func Test_Cache_Info(t *testing.T) {
 	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
 	assert.NoError(t, err)
 
-	os.Args = []string{"cleed", "--cache-info"}
+	os.Args = []string{"cleed", "--since", "30d"}
 
 	err = root.Cmd.Execute()
 	assert.NoError(t, err)
-	assert.Equal(t, fmt.Sprintf(`URL                       Last fetch           Fetch after
-https://example.com/atom  %s  %s
-https://example.com/rss   %s  %s
-`,
-		time.Unix(defaultCurrentTime.Unix(), 0).Format("2006-01-02 15:04:05"),
-		time.Unix(defaultCurrentTime.Unix()+300, 0).Format("2006-01-02 15:04:05"),
-		time.Unix(defaultCurrentTime.Unix(), 0).Format("2006-01-02 15:04:05"),
-		time.Unix(defaultCurrentTime.Unix()+300, 0).Format("2006-01-02 15:04:05"),
-	), out.String())
+	assert.Equal(t, `RSS Feed      • Item 1
+12 days ago   https://rss-feed.com/item-1/
+
+RSS Feed      • Item 3
+12 hours ago  https://rss-feed.com/item-3/
+
+`, out.String())
+
+	items, err := storage.LoadFeedArchive(server.URL)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, "Item 3", items[0].Title)
+	assert.Equal(t, "Item 1", items[1].Title)
+	assert.Equal(t, "Item 2", items[2].Title)
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "--search", "item 2", "--limit", "1"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed     Item 2
+78 days ago  https://rss-feed.com/item-2/
+
+`, out.String())
+}
+
+func Test_Feed_Archive_Retention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+	storage.Init("0.1.0")
+
+	config, err := storage.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ArchiveMaxAge = "60d"
+	config.ArchiveMaxItems = 3
+	err = storage.SaveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(createRSS([]*FeedItem{
+			{
+				Title:     "Item 4",
+				Link:      "https://rss-feed.com/item-4/",
+				Published: "Sun, 31 Dec 2023 12:00:00 GMT",
+			},
+			{
+				Title:     "Item 3",
+				Link:      "https://rss-feed.com/item-3/",
+				Published: "Sun, 31 Dec 2023 06:00:00 GMT",
+			},
+		})))
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), server.URL)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published1 := time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC)
+	published2 := time.Date(2023, 12, 10, 0, 0, 0, 0, time.UTC)
+	published3 := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	err = storage.SaveFeedArchive(server.URL, []*gofeed.Item{
+		{Title: "Item 0", Link: "https://rss-feed.com/item-0/", PublishedParsed: &published3},
+		{Title: "Item 1", Link: "https://rss-feed.com/item-1/", PublishedParsed: &published1},
+		{Title: "Item 2", Link: "https://rss-feed.com/item-2/", PublishedParsed: &published2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed      • Item 1
+12 days ago   https://rss-feed.com/item-1/
+
+RSS Feed      • Item 3
+18 hours ago  https://rss-feed.com/item-3/
+
+RSS Feed      • Item 4
+12 hours ago  https://rss-feed.com/item-4/
+
+`, out.String())
+
+	items, err := storage.LoadFeedArchive(server.URL)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, "Item 4", items[0].Title)
+	assert.Equal(t, "Item 3", items[1].Title)
+	assert.Equal(t, "Item 1", items[2].Title)
+}
+
+func Test_Feed_Meta_Title(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := createDefaultRSS()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s %s\n",
+			defaultCurrentTime.Unix(), server.URL, "color=3&title=Custom+Title",
+		),
+		), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `Custom Title    • Item 2
+1688 days ago   https://rss-feed.com/item-2/
+
+Custom Title    • Item 1
+15 minutes ago  https://rss-feed.com/item-1/
+
+`, out.String())
+}
+
+func Test_Feed_Colors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	rss := createDefaultRSS()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	err := storage.Init("0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := storage.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Styling = 1
+	config.Palette = []uint8{5}
+	config.ColorMap[5] = 200
+	err = storage.SaveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(configDir, "cleed_test", "lists", "default"),
+		[]byte(fmt.Sprintf("%d %s\n%d %s %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/a",
+			defaultCurrentTime.Unix(), server.URL+"/b", "color=3",
+		),
+		), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(out.String(), "\033[38;5;200mRSS Feed"))
+	assert.Equal(t, 2, strings.Count(out.String(), "\033[38;5;3mRSS Feed"))
+}
+
+func Test_Feed_Tags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := createDefaultRSS()
+	atom := createDefaultAtom()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/atom" {
+			w.Write([]byte(atom))
+			return
+		}
+		w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s %s\n%d %s %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/rss", "tags=go%2Csecurity",
+			defaultCurrentTime.Unix(), server.URL+"/atom", "tags=go",
+		),
+		), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "test"),
+		[]byte(fmt.Sprintf("%d %s %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/atom", "tags=release-notes",
+		),
+		), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err = run("--tag", "security")
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed        • Item 2
+1688 days ago   https://rss-feed.com/item-2/
+
+RSS Feed        • Item 1
+15 minutes ago  https://rss-feed.com/item-1/
+
+`, out.String())
+
+	err = run("--tag", "go", "--not-tag", "release-notes")
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed        Item 2
+1688 days ago   https://rss-feed.com/item-2/
+
+RSS Feed        Item 1
+15 minutes ago  https://rss-feed.com/item-1/
+
+`, out.String())
+
+	err = run("--tag", "Release-Notes,security")
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Atom Feed")
+	assert.Contains(t, out.String(), "RSS Feed")
+}
+
+func Test_Config_Dir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "--config-path"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(configDir, "cleed_test")+"\n", out.String())
+}
+
+func Test_Cache_Dir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "--cache-path"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(cacheDir, "cleed_test")+"\n", out.String())
+}
+
+func Test_Profile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer func() {
+		storage.SetProfile("work")
+		localStorageCleanup(t, storage)
+		storage.SetProfile("")
+		localStorageCleanup(t, storage)
+	}()
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "follow", "https://example.com", "--profile", "work"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "added 1 feed to list: default\n", out.String())
+
+	assert.FileExists(t, path.Join(configDir, "cleed_test", "profiles", "work", "lists", "default"))
+	assert.NoFileExists(t, path.Join(configDir, "cleed_test", "lists", "default"))
+	assert.NoFileExists(t, path.Join(configDir, "cleed_test", "config.json"))
+	assert.NoDirExists(t, path.Join(configDir, "cleed_test", "lists"))
+
+	t.Setenv("CLEED_PROFILE", "work")
+	storage.SetProfile("")
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "list", "default"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "https://example.com")
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "--config-path", "--profile", "../work"}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "invalid profile name: ../work")
+}
+
+func Test_Data_Dir_Env(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir := t.TempDir()
+	cacheDir := t.TempDir()
+	t.Setenv("CLEED_CONFIG_DIR", configDir)
+	t.Setenv("CLEED_CACHE_DIR", cacheDir)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "--config-path"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, configDir+"\n", out.String())
+	assert.FileExists(t, path.Join(configDir, "config.json"))
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	out.Reset()
+
+	os.Args = []string{"cleed", "--cache-path", "--profile", "work"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(cacheDir, "profiles", "work")+"\n", out.String())
+	assert.FileExists(t, path.Join(configDir, "profiles", "work", "config.json"))
+}
+
+func Test_Cache_Info(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	storage.Init("0.1.0")
+
+	cacheInfo := map[string]*_storage.CacheInfoItem{
+		"https://example.com/rss": {
+			URL:        "https://example.com/rss",
+			LastFetch:  time.Unix(defaultCurrentTime.Unix(), 0),
+			ETag:       "etag",
+			FetchAfter: time.Unix(defaultCurrentTime.Unix()+300, 0),
+		},
+		"https://example.com/atom": {
+			URL:        "https://example.com/atom",
+			LastFetch:  time.Unix(defaultCurrentTime.Unix(), 0),
+			ETag:       "",
+			FetchAfter: time.Unix(defaultCurrentTime.Unix()+300, 0),
+		},
+	}
+	err := storage.SaveCacheInfo(cacheInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "--cache-info"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`URL                       Last fetch           Fetch after
+https://example.com/atom  %s  %s
+https://example.com/rss   %s  %s
+`,
+		time.Unix(defaultCurrentTime.Unix(), 0).Format("2006-01-02 15:04:05"),
+		time.Unix(defaultCurrentTime.Unix()+300, 0).Format("2006-01-02 15:04:05"),
+		time.Unix(defaultCurrentTime.Unix(), 0).Format("2006-01-02 15:04:05"),
+		time.Unix(defaultCurrentTime.Unix()+300, 0).Format("2006-01-02 15:04:05"),
+	), out.String())
+}
+
+func Test_Feed_New_Backdated_Item(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := defaultCurrentTime
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	items := []*FeedItem{
+		{Title: "Item 1", Link: "https://rss-feed.com/item-1/", Published: "Sun, 31 Dec 2023 23:45:00 GMT"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(createRSS(items)))
+	}))
+	defer server.Close()
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("follow", server.URL)
+	assert.NoError(t, err)
+
+	err = run()
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed        • Item 1
+15 minutes ago  https://rss-feed.com/item-1/
+
+`, out.String())
+
+	// An item published before the previous fetch that appears in the feed
+	// later is new
+	now = defaultCurrentTime.Add(time.Hour)
+	items = append(items, &FeedItem{Title: "Item 2", Link: "https://rss-feed.com/item-2/", Published: "Sat, 30 Dec 2023 00:00:00 GMT"})
+
+	err = run()
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed    • Item 2
+2 days ago  https://rss-feed.com/item-2/
+
+RSS Feed    Item 1
+1 hour ago  https://rss-feed.com/item-1/
+
+`, out.String())
+
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCurrentTime.Add(time.Hour), states[server.URL]["https://rss-feed.com/item-2/"].FirstSeen.UTC())
+}
+
+func Test_Feed_Output(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := `<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<channel>
+		<title>RSS Feed</title>
+		<link>https://rss-feed.com/</link>
+		<item>
+			<title>Item 1, "quoted"</title>
+			<link>https://rss-feed.com/item-1/</link>
+			<guid>item-1</guid>
+			<pubDate>Sun, 31 Dec 2023 23:45:00 GMT</pubDate>
+			<category>go</category>
+			<category>security</category>
+			<dc:creator>Jane Doe</dc:creator>
+		</item>
+		<item>
+			<title>Item	2</title>
+			<link>https://rss-feed.com/item-2/</link>
+		</item>
+	</channel>
+</rss>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), server.URL+"/rss")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	run := func(args ...string) error {
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err = run("--output", "json")
+	assert.NoError(t, err)
+	assert.Equal(t, `[
+  {
+    "feedTitle": "RSS Feed",
+    "feedUrl": "`+server.URL+`/rss",
+    "title": "Item 1, \"quoted\"",
+    "link": "https://rss-feed.com/item-1/",
+    "guid": "item-1",
+    "published": "2023-12-31T23:45:00Z",
+    "updated": null,
+    "categories": [
+      "go",
+      "security"
+    ],
+    "authors": [
+      "Jane Doe"
+    ],
+    "isNew": true,
+    "isRead": false,
+    "score": 0
+  },
+  {
+    "feedTitle": "RSS Feed",
+    "feedUrl": "`+server.URL+`/rss",
+    "title": "Item\t2",
+    "link": "https://rss-feed.com/item-2/",
+    "guid": "",
+    "published": null,
+    "updated": null,
+    "categories": [],
+    "authors": [],
+    "isNew": true,
+    "isRead": false,
+    "score": 0
+  }
+]
+`, out.String())
+
+	// Items are no longer new after the first run
+	err = run("--output", "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, `feedTitle,feedUrl,title,link,guid,published,updated,categories,authors,isNew,isRead,score
+RSS Feed,`+server.URL+`/rss,"Item 1, ""quoted""",https://rss-feed.com/item-1/,item-1,2023-12-31T23:45:00Z,,"go,security",Jane Doe,false,false,0
+RSS Feed,`+server.URL+`/rss,Item	2,https://rss-feed.com/item-2/,,,,,,false,false,0
+`, out.String())
+
+	err = run("--output", "tsv")
+	assert.NoError(t, err)
+	assert.Equal(t, "feedTitle\tfeedUrl\ttitle\tlink\tguid\tpublished\tupdated\tcategories\tauthors\tisNew\tisRead\tscore\n"+
+		"RSS Feed\t"+server.URL+"/rss\tItem 1, \"quoted\"\thttps://rss-feed.com/item-1/\titem-1\t2023-12-31T23:45:00Z\t\tgo,security\tJane Doe\tfalse\tfalse\t0\n"+
+		"RSS Feed\t"+server.URL+"/rss\tItem 2\thttps://rss-feed.com/item-2/\t\t\t\t\t\tfalse\tfalse\t0\n", out.String())
+
+	err = run("--output", "ndjson", "--limit", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"feedTitle":"RSS Feed","feedUrl":"`+server.URL+`/rss","title":"Item 1, \"quoted\"","link":"https://rss-feed.com/item-1/","guid":"item-1","published":"2023-12-31T23:45:00Z","updated":null,"categories":["go","security"],"authors":["Jane Doe"],"isNew":false,"isRead":false,"score":0}
+`, out.String())
+
+	err = run("--output", "xml")
+	assert.EqualError(t, err, "invalid output format: xml, must be one of: json, ndjson, csv, tsv, atom, rss, jsonfeed")
+}
+
+func Test_Feed_Output_Aggregated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := `<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<channel>
+		<title>RSS Feed</title>
+		<link>https://rss-feed.com/</link>
+		<item>
+			<title>Item 1 &amp; more</title>
+			<link>https://rss-feed.com/item-1/</link>
+			<guid>item-1</guid>
+			<description>&lt;p&gt;Summary&lt;/p&gt;</description>
+			<pubDate>Sun, 31 Dec 2023 23:45:00 GMT</pubDate>
+			<category>go</category>
+			<dc:creator>Jane Doe</dc:creator>
+		</item>
+	</channel>
+</rss>`
+	atom := createDefaultAtom()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rss" {
+			w.Write([]byte(rss))
+		} else if r.URL.Path == "/atom" {
+			w.Write([]byte(atom))
+		}
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n%d %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/rss",
+			defaultCurrentTime.Unix(), server.URL+"/atom",
+		)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	run := func(args ...string) error {
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	// IDs are made of the feed URL and the item ID, so they are unique across feeds
+	rssItemID := "urn:cleed:" + url.QueryEscape(server.URL+"/rss") + ":item-1"
+	atomItemID := "urn:cleed:" + url.QueryEscape(server.URL+"/atom") + ":" + url.QueryEscape("https://atom-feed.com/item-1/")
+
+	err = run("--output", "atom", "--limit", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:cleed="https://github.com/radulucut/cleed">
+  <id>urn:cleed</id>
+  <title>cleed</title>
+  <updated>2024-01-01T00:00:00Z</updated>
+  <author>
+    <name>cleed</name>
+  </author>
+  <link rel="self" href="urn:cleed"></link>
+  <generator>cleed</generator>
+  <entry>
+    <id>`+rssItemID+`</id>
+    <title>Item 1 &amp; more</title>
+    <link rel="alternate" href="https://rss-feed.com/item-1/"></link>
+    <published>2023-12-31T23:45:00Z</published>
+    <updated>2023-12-31T23:45:00Z</updated>
+    <author>
+      <name>Jane Doe</name>
+    </author>
+    <category term="go"></category>
+    <summary type="html">&lt;p&gt;Summary&lt;/p&gt;</summary>
+    <source>
+      <id>`+server.URL+`/rss</id>
+      <title>RSS Feed</title>
+      <link rel="self" href="`+server.URL+`/rss"></link>
+      <link rel="alternate" href="https://rss-feed.com/"></link>
+      <cleed:id>item-1</cleed:id>
+    </source>
+  </entry>
+  <entry>
+    <id>`+atomItemID+`</id>
+    <title>Item 1</title>
+    <link rel="alternate" href="https://atom-feed.com/item-1/"></link>
+    <published>2023-12-31T06:00:00Z</published>
+    <updated>2023-12-31T06:00:00Z</updated>
+    <source>
+      <id>`+server.URL+`/atom</id>
+      <title>Atom Feed</title>
+      <link rel="self" href="`+server.URL+`/atom"></link>
+      <link rel="alternate" href="https://atom-feed.com/"></link>
+      <cleed:id>https://atom-feed.com/item-1/</cleed:id>
+    </source>
+  </entry>
+</feed>
+`, out.String())
+
+	err = run("--output", "rss", "--limit", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>cleed</title>
+    <description>Items aggregated by cleed</description>
+    <lastBuildDate>Mon, 01 Jan 2024 00:00:00 +0000</lastBuildDate>
+    <generator>cleed</generator>
+    <item>
+      <title>Item 1 &amp; more</title>
+      <link>https://rss-feed.com/item-1/</link>
+      <description>&lt;p&gt;Summary&lt;/p&gt;</description>
+      <dc:creator>Jane Doe</dc:creator>
+      <category>go</category>
+      <guid isPermaLink="false">`+rssItemID+`</guid>
+      <pubDate>Sun, 31 Dec 2023 23:45:00 +0000</pubDate>
+      <source url="`+server.URL+`/rss">RSS Feed</source>
+    </item>
+  </channel>
+</rss>
+`, out.String())
+
+	parsed, err := gofeed.NewParser().ParseString(out.String())
+	assert.NoError(t, err)
+	assert.Len(t, parsed.Items, 1)
+	assert.Equal(t, "Item 1 & more", parsed.Items[0].Title)
+
+	err = run("--output", "jsonfeed", "--limit", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "cleed",
+  "items": [
+    {
+      "id": "`+rssItemID+`",
+      "url": "https://rss-feed.com/item-1/",
+      "title": "Item 1 & more",
+      "content_html": "<p>Summary</p>",
+      "summary": "<p>Summary</p>",
+      "date_published": "2023-12-31T23:45:00Z",
+      "authors": [
+        {
+          "name": "Jane Doe"
+        }
+      ],
+      "tags": [
+        "go"
+      ],
+      "_source": {
+        "id": "item-1",
+        "title": "RSS Feed",
+        "feed_url": "`+server.URL+`/rss",
+        "home_page_url": "https://rss-feed.com/"
+      }
+    },
+    {
+      "id": "`+atomItemID+`",
+      "url": "https://atom-feed.com/item-1/",
+      "title": "Item 1",
+      "content_html": "",
+      "date_published": "2023-12-31T06:00:00Z",
+      "date_modified": "2023-12-31T06:00:00Z",
+      "_source": {
+        "id": "https://atom-feed.com/item-1/",
+        "title": "Atom Feed",
+        "feed_url": "`+server.URL+`/atom",
+        "home_page_url": "https://atom-feed.com/"
+      }
+    }
+  ]
+}
+`, out.String())
+
+	// Permalinks are unique across feeds, so they are kept as the GUID
+	err = run("--output", "rss", "--limit", "2", "--feed-url", "https://example.com/feed.xml")
+	assert.NoError(t, err)
+	parsed, err = gofeed.NewParser().ParseString(out.String())
+	assert.NoError(t, err)
+	assert.Len(t, parsed.Items, 2)
+	assert.Equal(t, rssItemID, parsed.Items[0].GUID)
+	assert.Equal(t, "https://atom-feed.com/item-1/", parsed.Items[1].GUID)
+	assert.Contains(t, out.String(), `<guid isPermaLink="true">https://atom-feed.com/item-1/</guid>`)
+
+	err = run("--output", "atom", "--limit", "1", "--feed-url", "https://example.com/feed.xml")
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `<link rel="self" href="https://example.com/feed.xml"></link>`)
+
+	err = run("--output", "json", "--feed-url", "https://example.com/feed.xml")
+	assert.EqualError(t, err, "--feed-url can only be used with --output atom, rss or jsonfeed")
+}
+
+func Test_Feed_Template(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := createDefaultRSS()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), server.URL+"/rss")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	run := func(args ...string) error {
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err = run("--template", "compact")
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed              • Item 2  https://rss-feed.com/item-2/
+RSS Feed              • Item 1  https://rss-feed.com/item-1/
+`, out.String())
+
+	err = run("--template", "markdown")
+	assert.NoError(t, err)
+	assert.Equal(t, `- [Item 2](https://rss-feed.com/item-2/) (RSS Feed, 2019-05-18)
+- [Item 1](https://rss-feed.com/item-1/) (RSS Feed, 2023-12-31)
+`, out.String())
+
+	err = run("--template", "org")
+	assert.NoError(t, err)
+	assert.Equal(t, `* [[https://rss-feed.com/item-2/][Item 2]] :RSS Feed:
+* [[https://rss-feed.com/item-1/][Item 1]] :RSS Feed:
+`, out.String())
+
+	err = run("--template", "{{.PublishedRelative}} {{upper .Item.Title}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "1688 days ago ITEM 2\n15 minutes ago ITEM 1\n", out.String())
+
+	tmplPath := path.Join(configDir, "cleed_test", "item.tmpl")
+	err = os.WriteFile(tmplPath, []byte("{{.Item.Link}}\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = run("--template", tmplPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://rss-feed.com/item-2/\nhttps://rss-feed.com/item-1/\n", out.String())
+
+	err = run("config", "--map-colors=7:8")
+	assert.NoError(t, err)
+	err = run("--template", "{{mapcolor 7}} {{mapcolor 10}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "8 10\n8 10\n", out.String())
+
+	err = run("--template", "{{.Item.Title")
+	assert.EqualError(t, err, "failed to parse template: template: item:1: unclosed action")
+
+	err = run("--template", "org", "--output", "json")
+	assert.EqualError(t, err, "--template cannot be used with --output")
+
+	// The default template from the config is used unless --template is set
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	err = run("config", "--template", "org")
+	assert.NoError(t, err)
+	assert.Equal(t, "template was updated\n", out.String())
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	err = run()
+	assert.NoError(t, err)
+	assert.Equal(t, `* [[https://rss-feed.com/item-2/][Item 2]] :RSS Feed:
+* [[https://rss-feed.com/item-1/][Item 1]] :RSS Feed:
+`, out.String())
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	err = run("--template=")
+	assert.NoError(t, err)
+	assert.Equal(t, `RSS Feed        Item 2
+1688 days ago   https://rss-feed.com/item-2/
+
+RSS Feed        Item 1
+15 minutes ago  https://rss-feed.com/item-1/
+
+`, out.String())
+
+	root, err = NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+	err = run("config", "--template", "{{.Nope")
+	assert.EqualError(t, err, "invalid template: template: item:1: unclosed action")
 }
@@ -0,0 +1,27 @@
+package cleed
+
+import "github.com/spf13/cobra"
+
+func (r *Root) initStar() {
+	cmd := &cobra.Command{
+		Use:   "star [item]",
+		Short: "Star an item",
+		Long: `Star an item. Starred items are kept after they are removed from the feed
+
+Examples:
+  # Star an item using its link or GUID
+  cleed star https://example.com/item-1
+
+  # Display starred items
+  cleed --starred
+`,
+		RunE: r.RunStar,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunStar(cmd *cobra.Command, args []string) error {
+	return r.feed.Star(args)
+}
@@ -0,0 +1,171 @@
+package cleed
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Star(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s %s\n", defaultCurrentTime.Unix(), "https://example.com", "title=Example")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir = path.Join(cacheDir, "cleed_test")
+	err = os.MkdirAll(cacheDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveFeedCache(bytes.NewBufferString(createDefaultRSS()), "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"https://rss-feed.com/item-1/": {Link: "https://rss-feed.com/item-1/", FirstSeen: defaultCurrentTime},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "star", "https://rss-feed.com/item-1/", "https://not-found.com"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `https://rss-feed.com/item-1/ was starred
+https://not-found.com was not found
+`, out.String())
+
+	starred, err := storage.LoadStarred()
+	assert.NoError(t, err)
+	assert.Len(t, starred, 1)
+	assert.Equal(t, "https://rss-feed.com/item-1/", starred[0].ID)
+	assert.Equal(t, "https://example.com", starred[0].FeedURL)
+	assert.Equal(t, "Example", starred[0].FeedTitle)
+	assert.Equal(t, "Item 1", starred[0].Title)
+	assert.Equal(t, "https://rss-feed.com/item-1/", starred[0].Link)
+	assert.Equal(t, time.Date(2023, 12, 31, 23, 45, 0, 0, time.UTC).Unix(), starred[0].Published.Unix())
+	assert.Equal(t, defaultCurrentTime, starred[0].StarredAt)
+}
+
+func Test_Star_Archived(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://example.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir = path.Join(cacheDir, "cleed_test")
+	err = os.MkdirAll(cacheDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveFeedCache(bytes.NewBufferString(createDefaultRSS()), "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = storage.SaveFeedArchive("https://example.com", []*gofeed.Item{
+		{Title: "Item 3", Link: "https://rss-feed.com/item-3/", PublishedParsed: &published},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"https://rss-feed.com/item-3/": {Link: "https://rss-feed.com/item-3/", FirstSeen: defaultCurrentTime},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "star", "https://rss-feed.com/item-3/"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://rss-feed.com/item-3/ was starred\n", out.String())
+
+	starred, err := storage.LoadStarred()
+	assert.NoError(t, err)
+	assert.Len(t, starred, 1)
+	assert.Equal(t, "https://rss-feed.com/item-3/", starred[0].ID)
+	assert.Equal(t, "RSS Feed", starred[0].FeedTitle)
+	assert.Equal(t, "Item 3", starred[0].Title)
+	assert.Equal(t, published.Unix(), starred[0].Published.Unix())
+}
@@ -0,0 +1,34 @@
+package cleed
+
+import "github.com/spf13/cobra"
+
+func (r *Root) initSync() {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync lists and settings with a git repository",
+		Long: `Sync lists and settings with a git repository
+
+The repository is the source of truth: local changes are committed, changes from
+the remote "origin" are merged and pushed back, then the merged lists and settings
+are applied locally. Conflicts in lists are resolved against the last common
+version: feeds removed on either side are removed and new feeds of both sides are
+added. Settings changed on both sides keep the local value and are reported. The
+first sync with a repository only adds the local lists to the ones in it.
+
+Examples:
+  # Set the repository to sync with
+  cleed config --sync-repo ~/cleed-lists
+
+  # Sync lists and settings
+  cleed sync
+`,
+		RunE: r.RunSync,
+		Args: cobra.NoArgs,
+	}
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunSync(cmd *cobra.Command, args []string) error {
+	return r.feed.Sync()
+}
@@ -0,0 +1,315 @@
+package cleed
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func runGit(t *testing.T, args ...string) {
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func listAddresses(t *testing.T, storage *_storage.LocalStorage, list string) []string {
+	items, err := storage.GetFeedsFromList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := make([]string, len(items))
+	for i := range items {
+		addresses[i] = items[i].Address
+	}
+	return addresses
+}
+
+func Test_Sync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	dir := t.TempDir()
+	remote := path.Join(dir, "remote.git")
+	repoA := path.Join(dir, "a")
+	repoB := path.Join(dir, "b")
+	runGit(t, "init", "--quiet", "--bare", "-b", "main", remote)
+	for _, repo := range []string{repoA, repoB} {
+		runGit(t, "init", "--quiet", "-b", "main", repo)
+		runGit(t, "-C", repo, "remote", "add", "origin", remote)
+	}
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storageA := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storageA)
+	storageB := _storage.NewLocalStorage("cleed_test_sync", timeMock)
+	defer localStorageCleanup(t, storageB)
+	feedA := internal.NewTerminalFeed(timeMock, printer, storageA)
+	feedA.SetAgent("cleed/test")
+	feedB := internal.NewTerminalFeed(timeMock, printer, storageB)
+	feedB.SetAgent("cleed/test")
+
+	run := func(storage *_storage.LocalStorage, feed *internal.TerminalFeed, args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run(storageA, feedA, "follow", "https://example.com")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "config", "--sync-repo", repoA)
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "config", "--summary", "1")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, `committed local changes
+pushed changes
+synced 1 list with `+repoA+`
+`, out.String())
+
+	err = run(storageB, feedB, "follow", "https://test.com")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "follow", "https://other.com", "--list", "other")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "config", "--sync-repo", repoB)
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, `committed local changes
+merged changes from origin/main
+pushed changes
+synced 2 lists with `+repoB+`
+`, out.String())
+	assert.Equal(t, []string{"https://example.com", "https://test.com"}, listAddresses(t, storageB, "default"))
+	config, err := storageB.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(1), config.Summary)
+
+	err = run(storageA, feedA, "follow", "https://a.com")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "follow", "https://b.com")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "sync")
+	assert.NoError(t, err)
+
+	err = run(storageA, feedA, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, `committed local changes
+merged changes from origin/main
+resolved conflict in lists/default
+pushed changes
+synced 2 lists with `+repoA+`
+`, out.String())
+	assert.Equal(t, []string{"https://example.com", "https://a.com", "https://test.com", "https://b.com"}, listAddresses(t, storageA, "default"))
+	assert.Equal(t, []string{"https://other.com"}, listAddresses(t, storageA, "other"))
+
+	err = run(storageB, feedB, "list", "other", "--remove")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com", "https://a.com", "https://test.com", "https://b.com"}, listAddresses(t, storageB, "default"))
+
+	err = run(storageA, feedA, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, `merged changes from origin/main
+pushed changes
+synced 1 list with `+repoA+`
+`, out.String())
+	lists, err := storageA.LoadLists()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default"}, lists)
+}
+
+func Test_Sync_Not_Set(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "sync"}
+
+	err = root.Cmd.Execute()
+	assert.EqualError(t, err, "sync repository is not set, set it with: cleed config --sync-repo <path>")
+}
+
+func Test_Sync_Clone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	dir := t.TempDir()
+	remote := path.Join(dir, "remote.git")
+	repoA := path.Join(dir, "a")
+	repoB := path.Join(dir, "b")
+	runGit(t, "init", "--quiet", "--bare", "-b", "main", remote)
+	runGit(t, "init", "--quiet", "-b", "main", repoA)
+	runGit(t, "-C", repoA, "remote", "add", "origin", remote)
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storageA := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storageA)
+	storageB := _storage.NewLocalStorage("cleed_test_sync", timeMock)
+	defer localStorageCleanup(t, storageB)
+	feedA := internal.NewTerminalFeed(timeMock, printer, storageA)
+	feedA.SetAgent("cleed/test")
+	feedB := internal.NewTerminalFeed(timeMock, printer, storageB)
+	feedB.SetAgent("cleed/test")
+
+	run := func(storage *_storage.LocalStorage, feed *internal.TerminalFeed, args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run(storageA, feedA, "follow", "https://team.com", "--list", "team")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "config", "--sync-repo", repoA)
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "config", "--summary", "1")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "sync")
+	assert.NoError(t, err)
+
+	// A clone of a remote that already has lists is merged with the local lists
+	runGit(t, "clone", "--quiet", remote, repoB)
+	err = run(storageB, feedB, "follow", "https://example.com")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "config", "--sync-repo", repoB)
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, `committed local changes
+pushed changes
+synced 2 lists with `+repoB+`
+`, out.String())
+	assert.Equal(t, []string{"https://team.com"}, listAddresses(t, storageB, "team"))
+	assert.Equal(t, []string{"https://example.com"}, listAddresses(t, storageB, "default"))
+	config, err := storageB.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(1), config.Summary)
+
+	err = run(storageA, feedA, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://team.com"}, listAddresses(t, storageA, "team"))
+	assert.Equal(t, []string{"https://example.com"}, listAddresses(t, storageA, "default"))
+}
+
+func Test_Sync_Conflicts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	dir := t.TempDir()
+	remote := path.Join(dir, "remote.git")
+	repoA := path.Join(dir, "a")
+	repoB := path.Join(dir, "b")
+	runGit(t, "init", "--quiet", "--bare", "-b", "main", remote)
+	for _, repo := range []string{repoA, repoB} {
+		runGit(t, "init", "--quiet", "-b", "main", repo)
+		runGit(t, "-C", repo, "remote", "add", "origin", remote)
+	}
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storageA := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storageA)
+	storageB := _storage.NewLocalStorage("cleed_test_sync", timeMock)
+	defer localStorageCleanup(t, storageB)
+	feedA := internal.NewTerminalFeed(timeMock, printer, storageA)
+	feedA.SetAgent("cleed/test")
+	feedB := internal.NewTerminalFeed(timeMock, printer, storageB)
+	feedB.SetAgent("cleed/test")
+
+	run := func(storage *_storage.LocalStorage, feed *internal.TerminalFeed, args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run(storageA, feedA, "follow", "https://example.com", "https://test.com")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "config", "--sync-repo", repoA)
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "sync")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "config", "--sync-repo", repoB)
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "sync")
+	assert.NoError(t, err)
+
+	// A feed removed on one side is not added back when both sides changed
+	err = run(storageA, feedA, "unfollow", "https://test.com")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "follow", "https://a.com")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "config", "--styling", "2")
+	assert.NoError(t, err)
+	err = run(storageA, feedA, "config", "--summary", "1")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "follow", "https://b.com")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "config", "--styling", "1")
+	assert.NoError(t, err)
+	err = run(storageB, feedB, "sync")
+	assert.NoError(t, err)
+
+	err = run(storageA, feedA, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, `committed local changes
+merged changes from origin/main
+resolved conflict in lists/default
+resolved conflict in settings.json
+setting styling was changed on both sides, kept the local value
+pushed changes
+synced 1 list with `+repoA+`
+`, out.String())
+	assert.Equal(t, []string{"https://example.com", "https://a.com", "https://b.com"}, listAddresses(t, storageA, "default"))
+	config, err := storageA.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(2), config.Styling)
+	assert.Equal(t, uint8(1), config.Summary)
+
+	err = run(storageB, feedB, "sync")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com", "https://a.com", "https://b.com"}, listAddresses(t, storageB, "default"))
+	config, err = storageB.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(2), config.Styling)
+	assert.Equal(t, uint8(1), config.Summary)
+}
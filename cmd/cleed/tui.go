@@ -0,0 +1,68 @@
+package cleed
+
+import (
+	"github.com/radulucut/cleed/internal"
+	"github.com/spf13/cobra"
+)
+
+func (r *Root) initTUI() {
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse items interactively",
+		Long: `Browse items interactively
+
+Keys:
+  j/k, up/down  move
+  g/G           go to the first or last item
+  enter         show the content of the item and mark it as read
+  r             mark the item as read or unread
+  s             star or unstar the item
+  l             filter by list, empty for all lists
+  /             search, empty to clear the search
+  R             refresh the feeds
+  q             go back or quit
+
+Examples:
+  # Browse items from all lists
+  cleed tui
+
+  # Browse unread items from a list
+  cleed tui --list mylist --unread
+`,
+		RunE: r.RunTUI,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("list", "L", "", "list to display feeds from")
+	flags.String("since", "", "display feeds since the last run (last), a specific date (e.g. 2024-01-01 12:03:04) or duration (e.g. 1d)")
+	flags.String("search", "", "search for items (title, categories)")
+	flags.Bool("unread", false, "display only unread items")
+	flags.StringSlice("tag", nil, "display items from feeds with any of the tags")
+	flags.StringSlice("not-tag", nil, "exclude items from feeds with any of the tags")
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunTUI(cmd *cobra.Command, args []string) error {
+	since, err := r.parseSinceFlag(cmd.Flag("since").Value.String())
+	if err != nil {
+		return err
+	}
+	tags, err := cmd.Flags().GetStringSlice("tag")
+	if err != nil {
+		return err
+	}
+	notTags, err := cmd.Flags().GetStringSlice("not-tag")
+	if err != nil {
+		return err
+	}
+	opts := &internal.FeedOptions{
+		List:    cmd.Flag("list").Value.String(),
+		Since:   since,
+		Unread:  cmd.Flag("unread").Changed,
+		Tags:    tags,
+		NotTags: notTags,
+	}
+	return r.feed.TUI(cmd.Flag("search").Value.String(), opts)
+}
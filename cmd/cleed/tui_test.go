@@ -0,0 +1,325 @@
+package cleed
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_TUI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	in := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(in, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := `<rss version="2.0">
+	<channel>
+		<title>RSS Feed</title>
+		<link>https://rss-feed.com/</link>
+		<item>
+			<title>Item 1</title>
+			<link>https://rss-feed.com/item-1/</link>
+			<description>&lt;p&gt;First paragraph&lt;/p&gt;&lt;ul&gt;&lt;li&gt;one&lt;/li&gt;&lt;li&gt;two&lt;/li&gt;&lt;/ul&gt;</description>
+			<pubDate>Sun, 31 Dec 2023 23:45:00 GMT</pubDate>
+		</item>
+		<item>
+			<title>Item 2</title>
+			<link>https://rss-feed.com/item-2/</link>
+			<pubDate>Sat, 18 May 2019 21:00:00 GMT</pubDate>
+		</item>
+	</channel>
+</rss>`
+	atom := createDefaultAtom()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rss" {
+			w.Write([]byte(rss))
+		} else if r.URL.Path == "/atom" {
+			w.Write([]byte(atom))
+		}
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), server.URL+"/rss")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "test"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), server.URL+"/atom")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	// Each key renders a frame, which ends with the footer
+	in.WriteString("j" + "s" + "\n" + "q" + "\x1b[A" + "r" + "\n" + "q" + "ltest\n" + "/item 2\n" + "q")
+	os.Args = []string{"cleed", "tui"}
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+
+	frames := strings.SplitAfter(out.String(), "quit\n")
+	assert.Equal(t, []string{
+		`cleed | list: all | 1/4
+>  • RSS Feed              15 minutes ago  Item 1
+   • Atom Feed             18 hours ago    Item 1
+   • Atom Feed             1594 days ago   Item 2
+   • RSS Feed              1688 days ago   Item 2
+j/k move  enter open  r read  s star  l list  / search  R refresh  q quit
+`,
+		`cleed | list: all | 2/4
+   • RSS Feed              15 minutes ago  Item 1
+>  • Atom Feed             18 hours ago    Item 1
+   • Atom Feed             1594 days ago   Item 2
+   • RSS Feed              1688 days ago   Item 2
+j/k move  enter open  r read  s star  l list  / search  R refresh  q quit
+`,
+	}, frames[:2])
+	assert.Equal(t, `cleed | list: all | 2/4
+   • RSS Feed              15 minutes ago  Item 1
+> *• Atom Feed             18 hours ago    Item 1
+   • Atom Feed             1594 days ago   Item 2
+   • RSS Feed              1688 days ago   Item 2
+starred
+Item 1
+Atom Feed | 18 hours ago (2023-12-31 06:00)
+https://atom-feed.com/item-1/
+
+no content
+j/k scroll  r read  s star  q back
+cleed | list: all | 2/4
+   • RSS Feed              15 minutes ago  Item 1
+> *  Atom Feed             18 hours ago    Item 1
+   • Atom Feed             1594 days ago   Item 2
+   • RSS Feed              1688 days ago   Item 2
+j/k move  enter open  r read  s star  l list  / search  R refresh  q quit
+`, frames[2])
+	assert.Equal(t, `cleed | list: all | 1/4
+>  • RSS Feed              15 minutes ago  Item 1
+  *  Atom Feed             18 hours ago    Item 1
+   • Atom Feed             1594 days ago   Item 2
+   • RSS Feed              1688 days ago   Item 2
+j/k move  enter open  r read  s star  l list  / search  R refresh  q quit
+`, frames[3])
+	assert.Equal(t, `cleed | list: all | 1/4
+>    RSS Feed              15 minutes ago  Item 1
+  *  Atom Feed             18 hours ago    Item 1
+   • Atom Feed             1594 days ago   Item 2
+   • RSS Feed              1688 days ago   Item 2
+marked as read
+`, frames[4][:strings.Index(frames[4], "marked as read\n")+len("marked as read\n")])
+	assert.Contains(t, out.String(), `Item 1
+RSS Feed | 15 minutes ago (2023-12-31 23:45)
+https://rss-feed.com/item-1/
+
+First paragraph
+
+- one
+- two
+j/k scroll  r read  s star  q back
+`)
+	assert.True(t, strings.HasSuffix(out.String(), `cleed | list: test | search: item 2 | 2/2
+   • Atom Feed             1594 days ago   Item 2
+> *  Atom Feed             18 hours ago    Item 1
+j/k move  enter open  r read  s star  l list  / search  R refresh  q quit
+`), out.String())
+
+	starred, err := storage.LoadStarred()
+	assert.NoError(t, err)
+	assert.Len(t, starred, 1)
+	assert.Equal(t, "https://atom-feed.com/item-1/", starred[0].Link)
+
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.True(t, states[server.URL+"/rss"]["https://rss-feed.com/item-1/"].Read)
+	assert.True(t, states[server.URL+"/atom"]["https://atom-feed.com/item-1/"].Read)
+	assert.False(t, states[server.URL+"/atom"]["https://atom-feed.com/item-2/"].Read)
+}
+
+func Test_TUI_Search_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	in := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(in, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := createDefaultRSS()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rss" {
+			w.Write([]byte(rss))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n%d %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/rss",
+			defaultCurrentTime.Unix(), server.URL+"/missing",
+		)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	// Fetch errors are shown in the status line instead of being printed
+	in.WriteString("q")
+	os.Args = []string{"cleed", "tui", "--search", "Item 2"}
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), `cleed | list: all | search: Item 2 | 1/2
+>  • RSS Feed              1688 days ago   Item 2
+   • RSS Feed              15 minutes ago  Item 1
+failed to fetch feed: `+server.URL+`/missing`), out.String())
+}
+
+func Test_TUI_Starred_Same_ID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	in := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(in, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both feeds have items with the same links, so their items have the same IDs
+	rss := createDefaultRSS()
+	otherRSS := strings.NewReplacer(
+		"RSS Feed", "Other Feed",
+		"Wed, 31 Dec 2023 23:45:00", "Sat, 30 Dec 2023 23:45:00",
+		"Sat, 18 May 2019 21:00:00", "Fri, 17 May 2019 21:00:00",
+	).Replace(rss)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rss" {
+			w.Write([]byte(rss))
+		} else {
+			w.Write([]byte(otherRSS))
+		}
+	}))
+	defer server.Close()
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n%d %s\n",
+			defaultCurrentTime.Unix(), server.URL+"/rss",
+			defaultCurrentTime.Unix(), server.URL+"/other",
+		)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.Init("0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = storage.AddStarred([]*_storage.StarredItem{{
+		ID:        "https://rss-feed.com/item-1/",
+		FeedURL:   server.URL + "/other",
+		FeedTitle: "Other Feed",
+		Title:     "Item 1",
+		Link:      "https://rss-feed.com/item-1/",
+		StarredAt: defaultCurrentTime,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	// Starring the item of one feed does not unstar the item of the other
+	in.WriteString("s" + "j" + "s" + "q")
+	os.Args = []string{"cleed", "tui"}
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `cleed | list: all | 1/4
+>  • RSS Feed              15 minutes ago  Item 1
+  *• Other Feed            1 day ago       Item 1
+`)
+	assert.Contains(t, out.String(), `> *• RSS Feed              15 minutes ago  Item 1
+  *• Other Feed            1 day ago       Item 1
+   • RSS Feed              1688 days ago   Item 2
+   • Other Feed            1689 days ago   Item 2
+starred
+`)
+	assert.Contains(t, out.String(), `  *• RSS Feed              15 minutes ago  Item 1
+>  • Other Feed            1 day ago       Item 1
+   • RSS Feed              1688 days ago   Item 2
+   • Other Feed            1689 days ago   Item 2
+unstarred
+`)
+
+	starred, err := storage.LoadStarred()
+	assert.NoError(t, err)
+	assert.Len(t, starred, 1)
+	assert.Equal(t, server.URL+"/rss", starred[0].FeedURL)
+}
@@ -276,9 +276,60 @@ https://test.com was removed from the list
 
 	assert.NoFileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com")))
 
-	b, err := os.ReadFile(path.Join(cacheDir, "feed_"+url.QueryEscape("https://test.com")))
+	b, err := readFeedCacheFile(path.Join(cacheDir, "feed_"+url.QueryEscape("https://test.com")))
 	if err != nil {
 		t.Fatal(err)
 	}
 	assert.Equal(t, "test", string(b))
 }
+
+func Test_Unfollow_Normalize_URL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	run := func(args ...string) error {
+		root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+		assert.NoError(t, err)
+		out.Reset()
+		os.Args = append([]string{"cleed"}, args...)
+		return root.Cmd.Execute()
+	}
+
+	err := run("follow", "https://Example.com/feed/", "https://test.com")
+	assert.NoError(t, err)
+
+	err = storage.SaveFeedCache(bytes.NewBufferString("example"), "https://example.com/feed/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = run("cache", "http://www.example.com/feed", "--dump")
+	assert.NoError(t, err)
+	assert.Equal(t, "example", out.String())
+
+	err = run("unfollow", "http://www.example.com/feed")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://www.example.com/feed was removed from the list\n", out.String())
+
+	items, err := storage.GetFeedsFromList("default")
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "https://test.com", items[0].Address)
+
+	cacheDir, err := storage.JoinCacheDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoFileExists(t, path.Join(cacheDir, "feed_"+url.QueryEscape("https://example.com/feed/")))
+}
@@ -0,0 +1,33 @@
+package cleed
+
+import "github.com/spf13/cobra"
+
+func (r *Root) initUnread() {
+	cmd := &cobra.Command{
+		Use:   "unread [item]",
+		Short: "Mark items as unread",
+		Long: `Mark items as unread
+
+Examples:
+  # Mark an item as unread using its link or GUID
+  cleed unread https://example.com/item-1
+
+  # Mark all items in a list as unread
+  cleed unread --list mylist
+
+  # Mark all items as unread
+  cleed unread --all
+`,
+		RunE: r.RunUnread,
+	}
+
+	flags := cmd.Flags()
+	flags.StringP("list", "L", "", "the list to mark items in")
+	flags.Bool("all", false, "mark all items")
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunUnread(cmd *cobra.Command, args []string) error {
+	return r.runMarkRead(cmd, args, false)
+}
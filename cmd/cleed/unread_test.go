@@ -0,0 +1,84 @@
+package cleed
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Unread_All(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listsDir := path.Join(configDir, "cleed_test", "lists")
+	err = os.MkdirAll(listsDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path.Join(listsDir, "default"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://example.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path.Join(listsDir, "test"),
+		[]byte(fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://test.com")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.SaveItemStates(map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"guid-1": {Link: "https://example.com/item-1", FirstSeen: defaultCurrentTime, Read: true},
+		},
+		"https://test.com": {
+			"guid-1": {Link: "https://test.com/item-1", FirstSeen: defaultCurrentTime, Read: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "unread", "--all"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "marked all items as unread\n", out.String())
+
+	states, err := storage.LoadItemStates()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*_storage.ItemState{
+		"https://example.com": {
+			"guid-1": {Link: "https://example.com/item-1", FirstSeen: defaultCurrentTime},
+		},
+		"https://test.com": {
+			"guid-1": {Link: "https://test.com/item-1", FirstSeen: defaultCurrentTime},
+		},
+	}, states)
+}
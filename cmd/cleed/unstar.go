@@ -0,0 +1,24 @@
+package cleed
+
+import "github.com/spf13/cobra"
+
+func (r *Root) initUnstar() {
+	cmd := &cobra.Command{
+		Use:   "unstar [item]",
+		Short: "Unstar an item",
+		Long: `Unstar an item
+
+Examples:
+  # Unstar an item using its link or GUID
+  cleed unstar https://example.com/item-1
+`,
+		RunE: r.RunUnstar,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	r.Cmd.AddCommand(cmd)
+}
+
+func (r *Root) RunUnstar(cmd *cobra.Command, args []string) error {
+	return r.feed.Unstar(args)
+}
@@ -0,0 +1,56 @@
+package cleed
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/radulucut/cleed/internal"
+	_storage "github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_Unstar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+
+	out := new(bytes.Buffer)
+	printer := internal.NewPrinter(nil, out, out)
+	storage := _storage.NewLocalStorage("cleed_test", timeMock)
+	defer localStorageCleanup(t, storage)
+
+	storage.Init("0.1.0")
+
+	err := storage.SaveStarred([]*_storage.StarredItem{
+		{ID: "guid-1", FeedURL: "https://example.com", Title: "Item 1", Link: "https://example.com/item-1", StarredAt: defaultCurrentTime},
+		{ID: "guid-2", FeedURL: "https://example.com", Title: "Item 2", Link: "https://example.com/item-2", StarredAt: defaultCurrentTime},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := internal.NewTerminalFeed(timeMock, printer, storage)
+	feed.SetAgent("cleed/test")
+
+	root, err := NewRoot("0.1.0", timeMock, printer, storage, feed)
+	assert.NoError(t, err)
+
+	os.Args = []string{"cleed", "unstar", "https://example.com/item-1", "guid-3"}
+
+	err = root.Cmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `https://example.com/item-1 was unstarred
+guid-3 was not found in the starred items
+`, out.String())
+
+	starred, err := storage.LoadStarred()
+	assert.NoError(t, err)
+	assert.Equal(t, []*_storage.StarredItem{
+		{ID: "guid-2", FeedURL: "https://example.com", Title: "Item 2", Link: "https://example.com/item-2", StarredAt: defaultCurrentTime},
+	}, starred)
+}
@@ -1,6 +1,9 @@
 package cleed
 
 import (
+	"compress/gzip"
+	"io"
+	"os"
 	"testing"
 	"time"
 
@@ -81,3 +84,17 @@ func createDefaultAtom() string {
 	</entry>
 </feed>`
 }
+
+func readFeedCacheFile(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
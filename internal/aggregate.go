@@ -0,0 +1,353 @@
+package internal
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	aggregatedFeedGenerator = "cleed"
+
+	// Namespace of the elements that keep the original metadata of the items
+	aggregatedFeedNamespace = "https://github.com/radulucut/cleed"
+)
+
+type atomFeed struct {
+	XMLName    xml.Name     `xml:"feed"`
+	XMLNS      string       `xml:"xmlns,attr"`
+	XMLNSCleed string       `xml:"xmlns:cleed,attr"`
+	ID         string       `xml:"id"`
+	Title      string       `xml:"title"`
+	Updated    string       `xml:"updated"`
+	Author     *atomPerson  `xml:"author"` // used for the entries without authors
+	Links      []*atomLink  `xml:"link"`
+	Generator  string       `xml:"generator"`
+	Entries    []*atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string          `xml:"id"`
+	Title      string          `xml:"title"`
+	Links      []*atomLink     `xml:"link"`
+	Published  string          `xml:"published,omitempty"`
+	Updated    string          `xml:"updated"`
+	Authors    []*atomPerson   `xml:"author"`
+	Categories []*atomCategory `xml:"category"`
+	Summary    *atomText       `xml:"summary"`
+	Content    *atomText       `xml:"content"`
+	Source     *atomSource     `xml:"source"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// atomSource attributes the entry to the feed it was copied from. EntryID is
+// the ID of the entry in that feed.
+type atomSource struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Links   []*atomLink `xml:"link"`
+	EntryID string      `xml:"cleed:id"`
+}
+
+type rssFeed struct {
+	XMLName   xml.Name    `xml:"rss"`
+	Version   string      `xml:"version,attr"`
+	XMLNSDC   string      `xml:"xmlns:dc,attr"`
+	XMLNSCont string      `xml:"xmlns:content,attr"`
+	Channel   *rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Generator     string     `xml:"generator"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title,omitempty"`
+	Link        string     `xml:"link,omitempty"`
+	Description string     `xml:"description,omitempty"`
+	Content     *rssCDATA  `xml:"content:encoded"`
+	Creators    []string   `xml:"dc:creator"`
+	Categories  []string   `xml:"category"`
+	GUID        *rssGUID   `xml:"guid"`
+	PubDate     string     `xml:"pubDate,omitempty"`
+	Source      *rssSource `xml:"source"`
+}
+
+type rssCDATA struct {
+	Body string `xml:",cdata"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// rssSource attributes the item to the feed it was copied from.
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Title string `xml:",chardata"`
+}
+
+type jsonFeed struct {
+	Version string          `json:"version"`
+	Title   string          `json:"title"`
+	FeedURL string          `json:"feed_url,omitempty"`
+	Items   []*jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	ContentHTML   string            `json:"content_html"`
+	Summary       string            `json:"summary,omitempty"`
+	DatePublished string            `json:"date_published,omitempty"`
+	DateModified  string            `json:"date_modified,omitempty"`
+	Authors       []*jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string          `json:"tags,omitempty"`
+	Source        *jsonFeedSource   `json:"_source"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// jsonFeedSource is an extension that attributes the item to the feed it was
+// copied from. ID is the ID of the item in that feed.
+type jsonFeedSource struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	FeedURL     string `json:"feed_url"`
+	HomePageURL string `json:"home_page_url,omitempty"`
+}
+
+// outputAggregatedFeed writes the items as a single Atom, RSS or JSON Feed.
+// Each item keeps its metadata and links back to the feed it comes from.
+func (f *TerminalFeed) outputAggregatedFeed(items []*FeedItem, opts *FeedOptions) error {
+	title := aggregatedFeedGenerator
+	if opts.List != "" {
+		title += ": " + opts.List
+	}
+	id := "urn:cleed"
+	if opts.List != "" {
+		id += ":" + url.PathEscape(opts.List)
+	}
+	now := f.time.Now().UTC()
+	switch opts.Output {
+	case OutputAtom:
+		// The feed ID is used if the URL the feed is published at is unknown
+		self := opts.FeedURL
+		if self == "" {
+			self = id
+		}
+		feed := &atomFeed{
+			XMLNS:      "http://www.w3.org/2005/Atom",
+			XMLNSCleed: aggregatedFeedNamespace,
+			ID:         id,
+			Title:      title,
+			Updated:    now.Format(time.RFC3339),
+			Author:     &atomPerson{Name: aggregatedFeedGenerator},
+			Links:      []*atomLink{{Rel: "self", Href: self}},
+			Generator:  aggregatedFeedGenerator,
+			Entries:    make([]*atomEntry, 0, len(items)),
+		}
+		for _, fi := range items {
+			feed.Entries = append(feed.Entries, newAtomEntry(fi, now))
+		}
+		return f.writeXML(feed)
+	case OutputRSS:
+		feed := &rssFeed{
+			Version:   "2.0",
+			XMLNSDC:   "http://purl.org/dc/elements/1.1/",
+			XMLNSCont: "http://purl.org/rss/1.0/modules/content/",
+			Channel: &rssChannel{
+				Title:         title,
+				Description:   "Items aggregated by cleed",
+				LastBuildDate: now.Format(time.RFC1123Z),
+				Generator:     aggregatedFeedGenerator,
+				Items:         make([]*rssItem, 0, len(items)),
+			},
+		}
+		for _, fi := range items {
+			feed.Channel.Items = append(feed.Channel.Items, newRSSItem(fi))
+		}
+		return f.writeXML(feed)
+	case OutputJSONFeed:
+		feed := &jsonFeed{
+			Version: "https://jsonfeed.org/version/1.1",
+			Title:   title,
+			FeedURL: opts.FeedURL,
+			Items:   make([]*jsonFeedItem, 0, len(items)),
+		}
+		for _, fi := range items {
+			feed.Items = append(feed.Items, newJSONFeedItem(fi))
+		}
+		enc := json.NewEncoder(f.printer.OutWriter)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(feed)
+	}
+	return nil
+}
+
+func (f *TerminalFeed) writeXML(v any) error {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	f.printer.Print(xml.Header, string(b), "\n")
+	return nil
+}
+
+func newAtomEntry(fi *FeedItem, now time.Time) *atomEntry {
+	published := recordTime(fi.Item.PublishedParsed)
+	updated := recordTime(fi.Item.UpdatedParsed)
+	entry := &atomEntry{
+		ID:    aggregatedItemID(fi),
+		Title: fi.Item.Title,
+		Source: &atomSource{
+			ID:      fi.FeedURL,
+			Title:   fi.Feed.Title,
+			Links:   []*atomLink{{Rel: "self", Href: fi.FeedURL}},
+			EntryID: itemID(fi.Item),
+		},
+	}
+	if fi.Item.Link != "" {
+		entry.Links = append(entry.Links, &atomLink{Rel: "alternate", Href: fi.Item.Link})
+	}
+	if fi.Feed.Link != "" {
+		entry.Source.Links = append(entry.Source.Links, &atomLink{Rel: "alternate", Href: fi.Feed.Link})
+	}
+	if published != nil {
+		entry.Published = published.Format(time.RFC3339)
+	}
+	// Atom entries must have an updated date
+	switch {
+	case updated != nil:
+		entry.Updated = updated.Format(time.RFC3339)
+	case published != nil:
+		entry.Updated = entry.Published
+	default:
+		entry.Updated = now.Format(time.RFC3339)
+	}
+	for _, a := range itemAuthors(fi.Item) {
+		name := a.Name
+		if name == "" {
+			name = a.Email
+		}
+		entry.Authors = append(entry.Authors, &atomPerson{Name: name, Email: a.Email})
+	}
+	for _, c := range fi.Item.Categories {
+		entry.Categories = append(entry.Categories, &atomCategory{Term: c})
+	}
+	if fi.Item.Description != "" {
+		entry.Summary = &atomText{Type: "html", Body: fi.Item.Description}
+	}
+	if fi.Item.Content != "" {
+		entry.Content = &atomText{Type: "html", Body: fi.Item.Content}
+	}
+	return entry
+}
+
+func newRSSItem(fi *FeedItem) *rssItem {
+	item := &rssItem{
+		Title:       fi.Item.Title,
+		Link:        fi.Item.Link,
+		Description: fi.Item.Description,
+		Categories:  fi.Item.Categories,
+		GUID:        &rssGUID{Value: aggregatedItemID(fi)},
+		Source:      &rssSource{URL: fi.FeedURL, Title: fi.Feed.Title},
+	}
+	// A permalink is unique across feeds, so the original ID is kept
+	if id := itemID(fi.Item); isPermaLink(id) {
+		item.GUID = &rssGUID{IsPermaLink: true, Value: id}
+	}
+	if fi.Item.Content != "" {
+		item.Content = &rssCDATA{Body: fi.Item.Content}
+	}
+	if published := recordTime(fi.Item.PublishedParsed); published != nil {
+		item.PubDate = published.Format(time.RFC1123Z)
+	}
+	for _, a := range itemAuthors(fi.Item) {
+		item.Creators = append(item.Creators, formatPerson(a))
+	}
+	return item
+}
+
+func newJSONFeedItem(fi *FeedItem) *jsonFeedItem {
+	item := &jsonFeedItem{
+		ID:          aggregatedItemID(fi),
+		URL:         fi.Item.Link,
+		Title:       fi.Item.Title,
+		ContentHTML: fi.Item.Content,
+		Summary:     fi.Item.Description,
+		Tags:        fi.Item.Categories,
+		Source: &jsonFeedSource{
+			ID:          itemID(fi.Item),
+			Title:       fi.Feed.Title,
+			FeedURL:     fi.FeedURL,
+			HomePageURL: fi.Feed.Link,
+		},
+	}
+	// JSON Feed items must have content, the field is empty if there is none
+	if item.ContentHTML == "" {
+		item.ContentHTML = item.Summary
+	}
+	if published := recordTime(fi.Item.PublishedParsed); published != nil {
+		item.DatePublished = published.Format(time.RFC3339)
+	}
+	if updated := recordTime(fi.Item.UpdatedParsed); updated != nil {
+		item.DateModified = updated.Format(time.RFC3339)
+	}
+	for _, a := range itemAuthors(fi.Item) {
+		item.Authors = append(item.Authors, &jsonFeedAuthor{Name: formatPerson(a)})
+	}
+	return item
+}
+
+// aggregatedItemID returns an ID that is unique across feeds, as the IDs of
+// items from different feeds may be the same. It is a URN made of the feed URL
+// and the ID of the item, so it is also a valid Atom ID.
+func aggregatedItemID(fi *FeedItem) string {
+	return "urn:cleed:" + url.QueryEscape(fi.FeedURL) + ":" + url.QueryEscape(itemID(fi.Item))
+}
+
+func isPermaLink(id string) bool {
+	u, err := url.Parse(id)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func itemAuthors(item *gofeed.Item) []*gofeed.Person {
+	authors := make([]*gofeed.Person, 0, len(item.Authors))
+	for _, a := range item.Authors {
+		if a != nil && (a.Name != "" || a.Email != "") {
+			authors = append(authors, a)
+		}
+	}
+	return authors
+}
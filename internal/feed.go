@@ -1,19 +1,21 @@
 package internal
 
 import (
-	"bufio"
 	"compress/gzip"
 	"context"
 	"encoding/xml"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/andybalholm/brotli"
@@ -69,11 +71,39 @@ func (f *TerminalFeed) DisplayConfig() error {
 		f.printer.Printf(" %d:%d", k, v)
 	}
 	f.printer.Println()
+	f.printer.Print("Palette:")
+	for _, c := range palette(config) {
+		f.printer.Print(" " + f.printer.ColorForeground(strconv.Itoa(int(c)), mapColor(c, config)))
+	}
+	if len(config.Palette) == 0 {
+		f.printer.Print(" (default)")
+	}
+	f.printer.Println()
 	summary := "disabled"
 	if config.Summary == 1 {
 		summary = "enabled"
 	}
 	f.printer.Println("Summary:", summary)
+	archiveMaxAge := "unlimited"
+	if config.ArchiveMaxAge != "" {
+		archiveMaxAge = config.ArchiveMaxAge
+	}
+	f.printer.Println("Archive max age:", archiveMaxAge)
+	archiveMaxItems := "unlimited"
+	if config.ArchiveMaxItems > 0 {
+		archiveMaxItems = strconv.FormatUint(uint64(config.ArchiveMaxItems), 10)
+	}
+	f.printer.Println("Archive max items:", archiveMaxItems)
+	syncRepo := "not set"
+	if config.SyncRepo != "" {
+		syncRepo = config.SyncRepo
+	}
+	f.printer.Println("Sync repository:", syncRepo)
+	tmpl := "default"
+	if config.Template != "" {
+		tmpl = config.Template
+	}
+	f.printer.Println("Template:", tmpl)
 	return nil
 }
 
@@ -111,6 +141,80 @@ func (f *TerminalFeed) SetSummary(v uint8) error {
 	return nil
 }
 
+func (f *TerminalFeed) SetArchiveMaxAge(v string) error {
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	if v != "" {
+		_, err = utils.ParseDuration(v)
+		if err != nil {
+			return utils.NewInternalError("invalid value for archive max age: " + v)
+		}
+	}
+	config.ArchiveMaxAge = v
+	err = f.storage.SaveConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to save config: " + err.Error())
+	}
+	f.printer.Println("archive max age was updated")
+	return nil
+}
+
+func (f *TerminalFeed) SetArchiveMaxItems(v uint) error {
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	config.ArchiveMaxItems = v
+	err = f.storage.SaveConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to save config: " + err.Error())
+	}
+	f.printer.Println("archive max items was updated")
+	return nil
+}
+
+func (f *TerminalFeed) SetSyncRepo(repo string) error {
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	if repo != "" {
+		repo, err = filepath.Abs(repo)
+		if err != nil {
+			return utils.NewInternalError("invalid sync repository: " + err.Error())
+		}
+	}
+	config.SyncRepo = repo
+	err = f.storage.SaveConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to save config: " + err.Error())
+	}
+	f.printer.Println("sync repository was updated")
+	return nil
+}
+
+func (f *TerminalFeed) SetTemplate(v string) error {
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	if v != "" {
+		_, err = f.ParseTemplate(v)
+		if err != nil {
+			return utils.NewInternalError("invalid template: " + err.Error())
+		}
+	}
+	config.Template = v
+	err = f.storage.SaveConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to save config: " + err.Error())
+	}
+	f.printer.Println("template was updated")
+	return nil
+}
+
 func (f *TerminalFeed) UpdateColorMap(mappings string) error {
 	config, err := f.storage.LoadConfig()
 	if err != nil {
@@ -148,6 +252,29 @@ func (f *TerminalFeed) UpdateColorMap(mappings string) error {
 	return nil
 }
 
+func (f *TerminalFeed) SetPalette(colors string) error {
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	config.Palette = nil
+	if colors != "" {
+		for _, c := range strings.Split(colors, ",") {
+			v, err := strconv.ParseUint(strings.TrimSpace(c), 10, 8)
+			if err != nil {
+				return utils.NewInternalError("failed to parse color: " + c)
+			}
+			config.Palette = append(config.Palette, uint8(v))
+		}
+	}
+	err = f.storage.SaveConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to save config: " + err.Error())
+	}
+	f.printer.Println("palette updated")
+	return nil
+}
+
 func (f *TerminalFeed) DisplayColorRange() {
 	styling := f.printer.GetStyling()
 	f.printer.SetStyling(true)
@@ -163,17 +290,17 @@ func (f *TerminalFeed) Follow(urls []string, list string) error {
 		return utils.NewInternalError("please provide at least one URL")
 	}
 	for i := range urls {
-		u, err := url.ParseRequestURI(urls[i])
+		u, err := utils.NormalizeURL(urls[i])
 		if err != nil {
 			return utils.NewInternalError("failed to parse URL: " + urls[i])
 		}
-		urls[i] = u.String()
+		urls[i] = u
 	}
-	err := f.storage.AddToList(urls, list)
+	added, err := f.storage.AddToList(urls, list)
 	if err != nil {
 		return utils.NewInternalError("failed to save feeds: " + err.Error())
 	}
-	f.printer.Printf("added %s to list: %s\n", utils.Pluralize(int64(len(urls)), "feed"), list)
+	f.printer.Printf("added %s to list: %s\n", utils.Pluralize(int64(added), "feed"), list)
 	return nil
 }
 
@@ -201,25 +328,137 @@ func (f *TerminalFeed) Lists() error {
 		f.printer.Println("default")
 		return nil
 	}
-	slices.Sort(lists)
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	slices.SortFunc(lists, compareListNames)
+	printed := make(map[string]struct{}, len(lists))
 	for i := range lists {
-		f.printer.Println(lists[i])
+		parts := strings.Split(lists[i], "/")
+		// Print the lists that only group other lists
+		for j := 1; j < len(parts); j++ {
+			parent := strings.Join(parts[:j], "/")
+			if _, ok := printed[parent]; !ok {
+				printed[parent] = struct{}{}
+				f.printer.Println(strings.Repeat("  ", j-1) + parts[j-1])
+			}
+		}
+		printed[lists[i]] = struct{}{}
+		name := strings.Repeat("  ", len(parts)-1) + parts[len(parts)-1]
+		if url, ok := config.RemoteLists[lists[i]]; ok {
+			f.printer.Printf("%s (remote: %s)\n", name, url)
+			continue
+		}
+		f.printer.Println(name)
 	}
 	return nil
 }
 
+// compareListNames orders the lists so nested lists follow their parent.
+func compareListNames(a, b string) int {
+	return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+}
+
 func (f *TerminalFeed) ListFeeds(list string) error {
 	feeds, err := f.storage.GetFeedsFromList(list)
 	if err != nil {
 		return utils.NewInternalError("failed to list feeds: " + err.Error())
 	}
 	for i := range feeds {
-		f.printer.Printf("%s  %s\n", feeds[i].AddedAt.Format("2006-01-02 15:04:05"), feeds[i].Address)
+		meta := formatListItemMeta(feeds[i].Meta)
+		if meta != "" {
+			meta = "  " + meta
+		}
+		f.printer.Printf("%s  %s%s\n", feeds[i].AddedAt.Format("2006-01-02 15:04:05"), feeds[i].Address, meta)
 	}
 	f.printer.Println("Total: " + utils.Pluralize(int64(len(feeds)), "feed"))
 	return nil
 }
 
+// SetFeedMeta sets the metadata of a feed in a list from key=value pairs. An
+// empty value removes the key.
+func (f *TerminalFeed) SetFeedMeta(list, address string, pairs []string) error {
+	meta := make(url.Values)
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return utils.NewInternalError("invalid metadata: " + pair + ", expected key=value")
+		}
+		switch k {
+		case storage.ListItemTitle, storage.ListItemNote:
+		case storage.ListItemColor:
+			if v == "" {
+				break
+			}
+			if _, err := strconv.ParseUint(v, 10, 8); err != nil {
+				return utils.NewInternalError("invalid color: " + v + ", expected a value between 0 and 255")
+			}
+		case storage.ListItemTags:
+			v = strings.Join(utils.ParseTags(v), ",")
+		case storage.ListItemSite:
+			if v == "" {
+				break
+			}
+			if _, err := url.ParseRequestURI(v); err != nil {
+				return utils.NewInternalError("invalid site: " + v)
+			}
+		default:
+			return utils.NewInternalError("unknown metadata key: " + k)
+		}
+		meta.Set(k, v)
+	}
+	found, err := f.storage.SetListItemMeta(list, address, meta)
+	if err != nil {
+		return utils.NewInternalError("failed to update list: " + err.Error())
+	}
+	if !found {
+		return utils.NewInternalError(fmt.Sprintf("feed %s was not found in list: %s", address, list))
+	}
+	f.printer.Printf("updated %s in list: %s\n", address, list)
+	return nil
+}
+
+// formatListItemMeta returns the metadata as key=value pairs in a fixed order.
+func formatListItemMeta(meta url.Values) string {
+	pairs := make([]string, 0, len(meta))
+	for _, k := range []string{storage.ListItemTitle, storage.ListItemColor, storage.ListItemTags, storage.ListItemSite, storage.ListItemNote} {
+		v := meta.Get(k)
+		if v == "" {
+			continue
+		}
+		if strings.ContainsAny(v, " \"") {
+			v = strconv.Quote(v)
+		}
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Tags displays all tags and the number of feeds tagged with each of them.
+func (f *TerminalFeed) Tags() error {
+	feeds, err := f.loadFeeds("")
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int64)
+	for _, feed := range feeds {
+		for _, tag := range feed.Tags() {
+			counts[tag]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	for _, tag := range tags {
+		f.printer.Printf("%s (%s)\n", tag, utils.Pluralize(counts[tag], "feed"))
+	}
+	f.printer.Println("Total: " + utils.Pluralize(int64(len(tags)), "tag"))
+	return nil
+}
+
 func (f *TerminalFeed) RenameList(oldName, newName string) error {
 	err := f.storage.RenameList(oldName, newName)
 	if err != nil {
@@ -247,166 +486,945 @@ func (f *TerminalFeed) RemoveList(list string) error {
 	return nil
 }
 
-func (f *TerminalFeed) ImportFromFile(path, list string) error {
-	fi, err := os.Open(path)
+// ImportFromFile imports the feeds of a file with one URL per line.
+func (f *TerminalFeed) ImportFromFile(path, list string, opts *ImportOptions) error {
+	return f.importSubscriptions(path, list, "file", opts, utils.ParseURLs)
+}
+
+func (f *TerminalFeed) ExportToFile(path, list string) error {
+	feeds, err := f.storage.GetFeedsFromList(list)
+	if err != nil {
+		return utils.NewInternalError("failed to list feeds: " + err.Error())
+	}
+	if len(feeds) == 0 {
+		f.printExported(path, 0)
+		return nil
+	}
+	fo, err := f.createOutput(path)
+	if err != nil {
+		return utils.NewInternalError("failed to create file: " + err.Error())
+	}
+	defer fo.Close()
+	for i := range feeds {
+		_, err = io.WriteString(fo, feeds[i].Address+"\n")
+		if err != nil {
+			return utils.NewInternalError("failed to write to file: " + err.Error())
+		}
+	}
+	f.printExported(path, len(feeds))
+	return nil
+}
+
+// openInput opens a file for reading. If path is "-", the input of the
+// printer is used instead, e.g. stdin.
+func (f *TerminalFeed) openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(f.printer.InReader), nil
+	}
+	return os.Open(path)
+}
+
+// createOutput creates a file for writing. If path is "-", the output of the
+// printer is used instead, e.g. stdout.
+func (f *TerminalFeed) createOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopWriteCloser{f.printer.OutWriter}, nil
+	}
+	return os.Create(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// printExported prints the number of exported feeds. The message is printed
+// to the error output when the feeds are exported to the output.
+func (f *TerminalFeed) printExported(path string, count int) {
+	printf := f.printer.Printf
+	if path == "-" {
+		printf = f.printer.ErrPrintf
+		path = "stdout"
+	}
+	if count == 0 {
+		printf("no feeds to export\n")
+		return
+	}
+	printf("exported %s to %s\n", utils.Pluralize(int64(count), "feed"), path)
+}
+
+type ImportOptions struct {
+	MapCategories bool // import the feeds of each category to a nested list
+	DryRun        bool // only report the status of the feeds
+	Validate      bool // fetch and parse the new feeds, invalid ones are not imported
+}
+
+// ImportFromOPML imports the feeds of all outlines of an OPML file. The title
+// and website of the feeds are saved in the list metadata. If the body has a
+// single category, it is the list itself.
+func (f *TerminalFeed) ImportFromOPML(path, list string, opts *ImportOptions) error {
+	return f.importSubscriptions(path, list, "OPML", opts, func(r io.Reader) ([]*utils.Subscription, error) {
+		opml := &utils.OPML{}
+		err := xml.NewDecoder(r).Decode(opml)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OPML: %v", err)
+		}
+		return opml.Subscriptions(), nil
+	})
+}
+
+// ImportFromNewsboat imports the feeds of a newsboat urls file. The tags of
+// the feeds are imported as tags and the first one is their category.
+func (f *TerminalFeed) ImportFromNewsboat(path, list string, opts *ImportOptions) error {
+	return f.importSubscriptions(path, list, "newsboat urls file", opts, utils.ParseNewsboatURLs)
+}
+
+// ImportFromBookmarks imports the links of a Netscape bookmark file as feeds.
+// The folders of the links are their categories.
+func (f *TerminalFeed) ImportFromBookmarks(path, list string, opts *ImportOptions) error {
+	return f.importSubscriptions(path, list, "bookmarks", opts, utils.ParseBookmarks)
+}
+
+// ImportFromJSON imports the feeds of a Miniflux or Google Reader API (e.g.
+// FreshRSS) JSON export.
+func (f *TerminalFeed) ImportFromJSON(path, list string, opts *ImportOptions) error {
+	return f.importSubscriptions(path, list, "JSON", opts, utils.ParseJSONSubscriptions)
+}
+
+func (f *TerminalFeed) importSubscriptions(
+	path, list, format string,
+	opts *ImportOptions,
+	parse func(r io.Reader) ([]*utils.Subscription, error),
+) error {
+	fi, err := f.openInput(path)
 	if err != nil {
 		return utils.NewInternalError("failed to open file: " + err.Error())
 	}
 	defer fi.Close()
-	urls := make([]string, 0)
-	scanner := bufio.NewScanner(fi)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
+	subs, err := parse(fi)
+	if err != nil {
+		return utils.NewInternalError(err.Error())
+	}
+	if len(subs) == 0 {
+		return utils.NewInternalError("no feeds found in " + format)
+	}
+	lists := make([]string, 0)
+	items := make(map[string][]*storage.ListItem)
+	check := opts.DryRun || opts.Validate
+	for _, sub := range subs {
+		u, err := utils.NormalizeURL(sub.URL)
+		if err == nil {
+			sub.URL = u
+		} else if !check {
+			// The checks report the malformed feeds with the others
+			f.printer.Print(f.printer.ColorForeground("skipped invalid feed URL: "+sub.URL+"\n", 11))
 			continue
 		}
-		if strings.HasPrefix(line, "#") {
+		l := list
+		if opts.MapCategories && sub.Folder != "" && storage.ValidListName(list+"/"+sub.Folder) {
+			l = list + "/" + sub.Folder
+		}
+		if _, ok := items[l]; !ok {
+			lists = append(lists, l)
+		}
+		items[l] = append(items[l], newListItemFromSubscription(sub))
+	}
+	if len(lists) == 0 {
+		return utils.NewInternalError("no valid feeds found in " + format)
+	}
+	if check {
+		items, err = f.checkImport(lists, items, opts.Validate)
+		if err != nil {
+			return err
+		}
+		if opts.DryRun {
+			f.printer.Println("dry run, no feeds were added")
+			return nil
+		}
+	}
+	for _, l := range lists {
+		if len(items[l]) == 0 {
 			continue
 		}
-		urls = append(urls, line)
+		added, err := f.storage.AddItemsToList(items[l], l)
+		if err != nil {
+			return utils.NewInternalError("failed to save feeds: " + err.Error())
+		}
+		f.printer.Printf("added %s to list: %s\n", utils.Pluralize(int64(added), "feed"), l)
+	}
+	return nil
+}
+
+// Statuses of the feeds of an import
+const (
+	importNew       = "new"
+	importFollowed  = "followed"  // already in a list
+	importDuplicate = "duplicate" // more than once in the import
+	importMalformed = "malformed"
+	importFailed    = "failed" // failed to fetch or parse
+)
+
+type importCheck struct {
+	list   string
+	item   *storage.ListItem
+	status string
+	detail string
+}
+
+// checkImport prints the status of the feeds to import and returns the feeds
+// that can be imported. If validate is true, the new feeds are fetched and
+// parsed.
+func (f *TerminalFeed) checkImport(
+	lists []string,
+	items map[string][]*storage.ListItem,
+	validate bool,
+) (map[string][]*storage.ListItem, error) {
+	all, err := f.storage.LoadLists()
+	if err != nil {
+		return nil, utils.NewInternalError("failed to load lists: " + err.Error())
+	}
+	slices.SortFunc(all, compareListNames)
+	followed := make(map[string][]string)
+	for _, l := range all {
+		feeds, err := f.storage.GetFeedsFromList(l)
+		if err != nil {
+			return nil, utils.NewInternalError("failed to list feeds: " + err.Error())
+		}
+		for _, feed := range feeds {
+			key := utils.URLKey(feed.Address)
+			followed[key] = append(followed[key], l)
+		}
+	}
+	checks := make([]*importCheck, 0)
+	seen := make(map[string]struct{})
+	for _, l := range lists {
+		for _, item := range items[l] {
+			c := &importCheck{list: l, item: item, status: importNew}
+			key := utils.URLKey(item.Address)
+			if _, err := utils.NormalizeURL(item.Address); err != nil {
+				c.status = importMalformed
+			} else if _, ok := seen[key]; ok {
+				c.status = importDuplicate
+			} else if in, ok := followed[key]; ok {
+				c.status = importFollowed
+				c.detail = strings.Join(in, ", ")
+			}
+			seen[key] = struct{}{}
+			checks = append(checks, c)
+		}
 	}
-	err = f.storage.AddToList(urls, list)
+	if validate {
+		wg := sync.WaitGroup{}
+		sem := make(chan struct{}, 8)
+		for _, c := range checks {
+			if c.status != importNew {
+				continue
+			}
+			wg.Add(1)
+			go func(c *importCheck) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				err := f.validateFeed(c.item.Address)
+				if err != nil {
+					c.status = importFailed
+					c.detail = err.Error()
+				}
+			}(c)
+		}
+		wg.Wait()
+	}
+	counts := make(map[string]int)
+	valid := make(map[string][]*storage.ListItem)
+	for _, c := range checks {
+		counts[c.status]++
+		line := runewidth.FillRight(c.status, len(importDuplicate)) + "  " + c.item.Address
+		if c.detail != "" {
+			line += "  (" + c.detail + ")"
+		}
+		switch c.status {
+		case importNew, importFollowed:
+			valid[c.list] = append(valid[c.list], c.item)
+			f.printer.Println(line)
+		default:
+			f.printer.Println(f.printer.ColorForeground(line, 11))
+		}
+	}
+	f.printer.Printf("%d new, %d followed, %d duplicate, %d malformed, %d failed\n",
+		counts[importNew],
+		counts[importFollowed],
+		counts[importDuplicate],
+		counts[importMalformed],
+		counts[importFailed],
+	)
+	return valid, nil
+}
+
+// validateFeed fetches and parses a feed without caching it.
+func (f *TerminalFeed) validateFeed(url string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return utils.NewInternalError("failed to save feeds: " + err.Error())
+		return err
+	}
+	req.Header.Set("User-Agent", f.agent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, application/json, text/xml")
+	res, err := f.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	_, err = gofeed.NewParser().Parse(res.Body)
+	return err
+}
+
+func newListItemFromSubscription(sub *utils.Subscription) *storage.ListItem {
+	meta := make(url.Values)
+	if sub.Title != "" {
+		meta.Set(storage.ListItemTitle, sub.Title)
+	}
+	if sub.Site != "" {
+		meta.Set(storage.ListItemSite, sub.Site)
+	}
+	if len(sub.Tags) > 0 {
+		meta.Set(storage.ListItemTags, strings.Join(sub.Tags, ","))
+	}
+	item := &storage.ListItem{Address: sub.URL}
+	if len(meta) > 0 {
+		item.Meta = meta
+	}
+	return item
+}
+
+func (f *TerminalFeed) BindRemoteOPML(list, opmlURL string) error {
+	u, err := url.ParseRequestURI(opmlURL)
+	if err != nil {
+		return utils.NewInternalError("failed to parse URL: " + opmlURL)
+	}
+	err = f.storage.SetRemoteList(list, u.String())
+	if err != nil {
+		return utils.NewInternalError("failed to save config: " + err.Error())
+	}
+	f.printer.Printf("list %s was bound to %s\n", list, u.String())
+	cacheInfo, err := f.storage.LoadCacheInfo()
+	if err != nil {
+		return utils.NewInternalError("failed to load cache info: " + err.Error())
+	}
+	// The list may not have the feeds of the last fetch, so it is always refetched
+	ci := &storage.CacheInfoItem{
+		URL:        u.String(),
+		LastFetch:  time.Unix(0, 0),
+		FetchAfter: time.Unix(0, 0),
+	}
+	cacheInfo[ci.URL] = ci
+	res, err := f.fetchOPML(ci)
+	if err == nil {
+		err = f.updateRemoteList(list, ci, res)
+	}
+	saveErr := f.storage.SaveCacheInfo(cacheInfo)
+	if saveErr != nil {
+		f.printer.ErrPrintln("failed to save cache informaton:", saveErr)
+	}
+	return err
+}
+
+func (f *TerminalFeed) UnbindRemoteOPML(list string) error {
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	if _, ok := config.RemoteLists[list]; !ok {
+		return utils.NewInternalError("list is not bound to a remote OPML: " + list)
 	}
-	f.printer.Printf("added %s to list: %s\n", utils.Pluralize(int64(len(urls)), "feed"), list)
+	err = f.storage.SetRemoteList(list, "")
+	if err != nil {
+		return utils.NewInternalError("failed to save config: " + err.Error())
+	}
+	f.printer.Printf("list %s was unbound\n", list)
 	return nil
 }
 
-func (f *TerminalFeed) ExportToFile(path, list string) error {
-	feeds, err := f.storage.GetFeedsFromList(list)
+// refreshRemoteLists updates the lists bound to a remote OPML. If list is not
+// empty, only that list and its nested lists are refreshed. The OPMLs are
+// fetched like feeds, so unchanged ones and the ones fetched recently are
+// skipped. Errors are printed and the list is kept.
+func (f *TerminalFeed) refreshRemoteLists(list string, config *storage.Config) {
+	lists := make([]string, 0, len(config.RemoteLists))
+	for name := range config.RemoteLists {
+		if list == "" || list == name || storage.IsNestedList(name, list) {
+			lists = append(lists, name)
+		}
+	}
+	if len(lists) == 0 {
+		return
+	}
+	slices.Sort(lists)
+	cacheInfo, err := f.storage.LoadCacheInfo()
 	if err != nil {
-		return utils.NewInternalError("failed to list feeds: " + err.Error())
+		f.printer.ErrPrintln("failed to load cache info:", err)
+		return
 	}
-	if len(feeds) == 0 {
-		f.printer.Println("no feeds to export")
+	cis := make([]*storage.CacheInfoItem, len(lists))
+	results := make([]*FetchResult, len(lists))
+	errs := make([]error, len(lists))
+	wg := sync.WaitGroup{}
+	for i, name := range lists {
+		url := config.RemoteLists[name]
+		ci := cacheInfo[url]
+		if ci == nil {
+			ci = &storage.CacheInfoItem{
+				URL:        url,
+				LastFetch:  time.Unix(0, 0),
+				FetchAfter: time.Unix(0, 0),
+			}
+			cacheInfo[url] = ci
+		}
+		cis[i] = ci
+		// Lists bound to the same OPML share its cache info, so it is
+		// fetched once
+		if slices.Index(cis, ci) != i {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = f.fetchOPML(cis[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, name := range lists {
+		j := slices.Index(cis, cis[i])
+		err := errs[j]
+		if err == nil {
+			err = f.updateRemoteList(name, cis[i], results[j])
+		}
+		if err != nil {
+			f.printer.ErrPrintf("failed to refresh list: %s: %v\n", name, err)
+		}
+	}
+	err = f.storage.SaveCacheInfo(cacheInfo)
+	if err != nil {
+		f.printer.ErrPrintln("failed to save cache informaton:", err)
+	}
+}
+
+func (f *TerminalFeed) fetchOPML(ci *storage.CacheInfoItem) (*FetchResult, error) {
+	res, err := f.fetch(ci, "text/x-opml, application/xml, text/xml")
+	if err != nil {
+		return nil, utils.NewInternalError("failed to fetch OPML: " + err.Error())
+	}
+	return res, nil
+}
+
+// updateRemoteList sets the feeds of a list to the ones of its fetched OPML.
+// The cache info is updated only if the list was updated, so a broken OPML is
+// fetched again on the next run.
+func (f *TerminalFeed) updateRemoteList(list string, ci *storage.CacheInfoItem, res *FetchResult) error {
+	if res.FetchAfter.After(ci.FetchAfter) {
+		ci.FetchAfter = res.FetchAfter
+	}
+	if !res.Changed {
 		return nil
 	}
-	fo, err := os.Create(path)
+	opml, err := f.parseOPML(ci.URL)
+	if err != nil {
+		return utils.NewInternalError("failed to parse OPML: " + err.Error())
+	}
+	subs := opml.Subscriptions()
+	items := make([]*storage.ListItem, len(subs))
+	for i := range subs {
+		items[i] = newListItemFromSubscription(subs[i])
+	}
+	added, removed, err := f.storage.UpdateListFromRemote(list, items)
+	if err != nil {
+		return utils.NewInternalError("failed to update list: " + err.Error())
+	}
+	ci.ETag = res.ETag
+	ci.LastFetch = f.time.Now()
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	f.printer.Printf("list %s was updated: added %s, removed %s\n",
+		list,
+		utils.Pluralize(int64(len(added)), "feed"),
+		utils.Pluralize(int64(len(removed)), "feed"),
+	)
+	return nil
+}
+
+func (f *TerminalFeed) parseOPML(url string) (*utils.OPML, error) {
+	fc, err := f.storage.OpenFeedCache(url)
+	if err != nil {
+		return nil, err
+	}
+	defer fc.Close()
+	opml := &utils.OPML{}
+	err = xml.NewDecoder(fc).Decode(opml)
+	if err != nil {
+		return nil, err
+	}
+	return opml, nil
+}
+
+// ExportToOPML exports the feeds of a list and its nested lists to an OPML
+// file. If list is empty, all lists are exported, each as a category.
+func (f *TerminalFeed) ExportToOPML(path, list string) error {
+	var lists []string
+	var err error
+	if list == "" {
+		lists, err = f.storage.LoadLists()
+	} else {
+		lists, err = f.storage.LoadListTree(list)
+	}
+	if err != nil {
+		return utils.NewInternalError("failed to load lists: " + err.Error())
+	}
+	slices.SortFunc(lists, compareListNames)
+	opml := &utils.OPML{
+		Version: "2.0",
+		Head:    utils.Head{Title: "cleed"},
+	}
+	categories := make(map[string]*utils.Outline)
+	if list != "" {
+		opml.Head.Title = list
+		categories[list] = &utils.Outline{Text: list, Title: list}
+		opml.Body.Oultines = append(opml.Body.Oultines, categories[list])
+	}
+	var category func(name string) *utils.Outline
+	category = func(name string) *utils.Outline {
+		if o, ok := categories[name]; ok {
+			return o
+		}
+		i := strings.LastIndex(name, "/")
+		o := &utils.Outline{Text: name[i+1:], Title: name[i+1:]}
+		if i == -1 {
+			opml.Body.Oultines = append(opml.Body.Oultines, o)
+		} else {
+			p := category(name[:i])
+			p.Outlines = append(p.Outlines, o)
+		}
+		categories[name] = o
+		return o
+	}
+	count := 0
+	for _, l := range lists {
+		feeds, err := f.storage.GetFeedsFromList(l)
+		if err != nil {
+			return utils.NewInternalError("failed to list feeds: " + err.Error())
+		}
+		if len(feeds) == 0 {
+			continue
+		}
+		c := category(l)
+		for _, item := range feeds {
+			c.Outlines = append(c.Outlines, f.newOutlineFromListItem(item))
+		}
+		count += len(feeds)
+	}
+	if count == 0 {
+		f.printExported(path, 0)
+		return nil
+	}
+	b, err := xml.MarshalIndent(opml, "", "  ")
+	if err != nil {
+		return utils.NewInternalError("failed to encode OPML: " + err.Error())
+	}
+	fo, err := f.createOutput(path)
 	if err != nil {
 		return utils.NewInternalError("failed to create file: " + err.Error())
 	}
 	defer fo.Close()
-	for i := range feeds {
-		_, err = fo.WriteString(feeds[i].Address + "\n")
+	_, err = fo.Write(append([]byte(xml.Header), append(b, '\n')...))
+	if err != nil {
+		return utils.NewInternalError("failed to write to file: " + err.Error())
+	}
+	f.printExported(path, count)
+	return nil
+}
+
+// newOutlineFromListItem returns the outline of a feed. The title and website
+// are taken from the list metadata or, if missing, from the parsed feed.
+func (f *TerminalFeed) newOutlineFromListItem(item *storage.ListItem) *utils.Outline {
+	o := &utils.Outline{
+		Type:    "rss",
+		XMLURL:  item.Address,
+		Title:   item.Meta.Get(storage.ListItemTitle),
+		HTMLURL: item.Meta.Get(storage.ListItemSite),
+	}
+	if tags := item.Tags(); len(tags) > 0 {
+		o.Category = strings.Join(tags, ",")
+	}
+	if o.Title == "" || o.HTMLURL == "" {
+		if feed, err := f.storage.LoadFeedSnapshot(item.Address); err == nil {
+			if o.Title == "" {
+				o.Title = feed.Title
+			}
+			if o.HTMLURL == "" {
+				o.HTMLURL = feed.Link
+			}
+		}
+	}
+	o.Text = o.Title
+	if o.Text == "" {
+		o.Text = item.Address
+	}
+	return o
+}
+
+func (f *TerminalFeed) ShowConfigPath() error {
+	path, err := f.storage.JoinConfigDir("")
+	if err != nil {
+		return utils.NewInternalError("failed to get config path: " + err.Error())
+	}
+	f.printer.Println(path)
+	return nil
+}
+
+func (f *TerminalFeed) ShowCachePath() error {
+	path, err := f.storage.JoinCacheDir("")
+	if err != nil {
+		return utils.NewInternalError("failed to get cache path: " + err.Error())
+	}
+	f.printer.Println(path)
+	return nil
+}
+
+func (f *TerminalFeed) ShowCacheInfo() error {
+	cacheInfo, err := f.storage.LoadCacheInfo()
+	if err != nil {
+		return utils.NewInternalError("failed to load cache info: " + err.Error())
+	}
+	cellMax := [1]int{}
+	items := make([]*storage.CacheInfoItem, 0, len(cacheInfo))
+	for k, v := range cacheInfo {
+		cellMax[0] = max(cellMax[0], len(k))
+		items = append(items, v)
+	}
+	f.printer.Print(runewidth.FillRight("URL", cellMax[0]))
+	f.printer.Println("  Last fetch           Fetch after")
+	slices.SortFunc(items, func(a, b *storage.CacheInfoItem) int {
+		if a.URL < b.URL {
+			return -1
+		}
+		if a.URL > b.URL {
+			return 1
+		}
+		return 0
+	})
+	for i := range items {
+		f.printer.Print(runewidth.FillRight(items[i].URL, cellMax[0]))
+		f.printer.Printf("  %s  %s\n", items[i].LastFetch.Format("2006-01-02 15:04:05"), items[i].FetchAfter.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+func (f *TerminalFeed) ShowCacheSizes() error {
+	sizes, err := f.storage.FeedCacheSizes()
+	if err != nil {
+		return utils.NewInternalError("failed to load cache sizes: " + err.Error())
+	}
+	cellMax := [1]int{len("URL")}
+	urls := make([]string, 0, len(sizes))
+	var total int64
+	for k, v := range sizes {
+		cellMax[0] = max(cellMax[0], len(k))
+		urls = append(urls, k)
+		total += v
+	}
+	slices.Sort(urls)
+	f.printer.Print(runewidth.FillRight("URL", cellMax[0]))
+	f.printer.Println("  Size")
+	for i := range urls {
+		f.printer.Print(runewidth.FillRight(urls[i], cellMax[0]))
+		f.printer.Printf("  %s\n", utils.FormatBytes(sizes[urls[i]]))
+	}
+	f.printer.Printf("Total: %s\n", utils.FormatBytes(total))
+	return nil
+}
+
+// ClearCache removes the cache of the given feeds and of the feeds in list, or
+// the whole cache if all is true.
+func (f *TerminalFeed) ClearCache(urls []string, list string, all bool) error {
+	if all {
+		err := f.storage.ClearCache()
 		if err != nil {
-			return utils.NewInternalError("failed to write to file: " + err.Error())
+			return utils.NewInternalError("failed to clear cache: " + err.Error())
+		}
+		f.printer.Println("cache was cleared")
+		return nil
+	}
+	if list != "" {
+		feeds, err := f.loadFeeds(list)
+		if err != nil {
+			return err
+		}
+		if len(feeds) == 0 {
+			return utils.NewInternalError("no feeds found in list: " + list)
+		}
+		for url := range feeds {
+			urls = append(urls, url)
 		}
 	}
-	f.printer.Printf("exported %s to %s\n", utils.Pluralize(int64(len(feeds)), "feed"), path)
+	if len(urls) == 0 {
+		return utils.NewInternalError("please provide a feed, a list or --all")
+	}
+	urls = f.followedAddresses(urls)
+	err := f.storage.RemoveFeedCaches(urls)
+	if err != nil {
+		return utils.NewInternalError("failed to clear cache: " + err.Error())
+	}
+	f.printer.Printf("cleared cache of %s\n", utils.Pluralize(int64(len(urls)), "feed"))
 	return nil
 }
 
-func (f *TerminalFeed) ImportFromOPML(path, list string) error {
-	b, err := os.ReadFile(path)
+func (f *TerminalFeed) DumpCache(url string) error {
+	url = f.followedAddresses([]string{url})[0]
+	fc, err := f.storage.OpenFeedCache(url)
 	if err != nil {
-		return utils.NewInternalError("failed to read file: " + err.Error())
+		return utils.NewInternalError("failed to open feed cache: " + err.Error())
 	}
-	opml := &utils.OPML{}
-	err = xml.Unmarshal(b, opml)
+	defer fc.Close()
+	_, err = io.Copy(f.printer.OutWriter, fc)
 	if err != nil {
-		return utils.NewInternalError("failed to parse OPML: " + err.Error())
+		return utils.NewInternalError("failed to read feed cache: " + err.Error())
 	}
-	urls := make([]string, 0, len(opml.Body.Oultines))
-	if len(opml.Body.Oultines) == 0 {
-		return utils.NewInternalError("no feeds found in OPML")
+	return nil
+}
+
+// followedAddresses returns the addresses under which the feeds are followed,
+// which are also the names of their caches. Feeds are matched by their URL
+// key and the URLs of feeds that are not followed are returned as they are.
+func (f *TerminalFeed) followedAddresses(urls []string) []string {
+	feeds, err := f.loadFeeds("")
+	if err != nil {
+		return urls
 	}
-	outlines := opml.Body.Oultines[0].Outlines
-	for _, o := range outlines {
-		urls = append(urls, o.XMLURL)
+	addresses := make(map[string]string, len(feeds))
+	for address := range feeds {
+		addresses[utils.URLKey(address)] = address
 	}
-	err = f.storage.AddToList(urls, list)
+	res := make([]string, len(urls))
+	for i := range urls {
+		res[i] = urls[i]
+		if _, ok := feeds[urls[i]]; ok {
+			continue
+		}
+		if address, ok := addresses[utils.URLKey(urls[i])]; ok {
+			res[i] = address
+		}
+	}
+	return res
+}
+
+func (f *TerminalFeed) PruneCache() error {
+	removed, err := f.storage.PruneFeedCaches()
 	if err != nil {
-		return utils.NewInternalError("failed to save feeds: " + err.Error())
+		return utils.NewInternalError("failed to prune cache: " + err.Error())
 	}
-	f.printer.Printf("added %s to list: %s\n", utils.Pluralize(int64(len(urls)), "feed"), list)
+	slices.Sort(removed)
+	for i := range removed {
+		f.printer.Println(removed[i])
+	}
+	f.printer.Printf("pruned cache of %s\n", utils.Pluralize(int64(len(removed)), "feed"))
 	return nil
 }
 
-func (f *TerminalFeed) ExportToOPML(path, list string) error {
-	feeds, err := f.storage.GetFeedsFromList(list)
+type duplicateFeed struct {
+	address string
+	addedAt time.Time
+	lists   []string
+}
+
+// Duplicates prints the feeds that are followed under different URLs (e.g.
+// http and https, with and without "www."). If merge is true, the duplicates
+// are replaced in all lists by the feed that is kept.
+func (f *TerminalFeed) Duplicates(merge bool) error {
+	lists, err := f.storage.LoadLists()
 	if err != nil {
-		return utils.NewInternalError("failed to list feeds: " + err.Error())
+		return utils.NewInternalError("failed to load lists: " + err.Error())
 	}
-	if len(feeds) == 0 {
-		f.printer.Println("no feeds to export")
+	slices.SortFunc(lists, compareListNames)
+	keys := make([]string, 0)
+	groups := make(map[string][]*duplicateFeed)
+	for _, list := range lists {
+		items, err := f.storage.GetFeedsFromList(list)
+		if err != nil {
+			return utils.NewInternalError("failed to list feeds: " + err.Error())
+		}
+		for _, item := range items {
+			key := utils.URLKey(item.Address)
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			i := slices.IndexFunc(groups[key], func(d *duplicateFeed) bool {
+				return d.address == item.Address
+			})
+			if i == -1 {
+				groups[key] = append(groups[key], &duplicateFeed{address: item.Address, addedAt: item.AddedAt})
+				i = len(groups[key]) - 1
+			}
+			d := groups[key][i]
+			if item.AddedAt.Before(d.addedAt) {
+				d.addedAt = item.AddedAt
+			}
+			d.lists = append(d.lists, list)
+		}
+	}
+	replacements := make(map[string]string)
+	for _, key := range keys {
+		feeds := groups[key]
+		if len(feeds) < 2 {
+			continue
+		}
+		// Keep https feeds, then the one that was added first
+		slices.SortStableFunc(feeds, func(a, b *duplicateFeed) int {
+			ha, hb := strings.HasPrefix(a.address, "https:"), strings.HasPrefix(b.address, "https:")
+			if ha != hb {
+				if ha {
+					return -1
+				}
+				return 1
+			}
+			if c := a.addedAt.Compare(b.addedAt); c != 0 {
+				return c
+			}
+			return len(a.address) - len(b.address)
+		})
+		f.printer.Printf("%s  (%s)\n", feeds[0].address, strings.Join(feeds[0].lists, ", "))
+		for _, d := range feeds[1:] {
+			f.printer.Printf("  %s  (%s)\n", d.address, strings.Join(d.lists, ", "))
+			replacements[d.address] = feeds[0].address
+		}
+	}
+	if len(replacements) == 0 {
+		f.printer.Println("no duplicates found")
 		return nil
 	}
+	if !merge {
+		f.printer.Printf("found %s, merge them with: cleed duplicates --merge\n", utils.Pluralize(int64(len(replacements)), "duplicate"))
+		return nil
+	}
+	err = f.storage.ReplaceInLists(replacements)
+	if err != nil {
+		return utils.NewInternalError("failed to merge duplicates: " + err.Error())
+	}
+	f.printer.Printf("merged %s\n", utils.Pluralize(int64(len(replacements)), "duplicate"))
+	return nil
+}
+
+func (f *TerminalFeed) Backup(path string, includeCache bool) error {
 	fo, err := os.Create(path)
 	if err != nil {
 		return utils.NewInternalError("failed to create file: " + err.Error())
 	}
 	defer fo.Close()
-	fmt.Fprintf(fo, `%s<opml version="1.0">
-  <head>
-    <title>%s</title>
-  </head>
-  <body>
-	<outline text="%s">
-`, xml.Header, list, list)
-	for i := range feeds {
-		fmt.Fprintf(fo, `      <outline xmlUrl="%s"/>
-`, feeds[i].Address)
+	_, err = f.storage.Backup(fo, includeCache)
+	if err != nil {
+		return utils.NewInternalError("failed to create backup: " + err.Error())
 	}
-	fmt.Fprint(fo, `	</outline>
-  </body>
-</opml>`)
-	f.printer.Printf("exported %s to %s\n", utils.Pluralize(int64(len(feeds)), "feed"), path)
+	f.printer.Printf("backup saved to %s\n", path)
 	return nil
 }
 
-func (f *TerminalFeed) ShowConfigPath() error {
-	path, err := f.storage.JoinConfigDir("")
+func (f *TerminalFeed) Restore(path string) error {
+	fi, err := os.Open(path)
+	if err != nil {
+		return utils.NewInternalError("failed to open file: " + err.Error())
+	}
+	defer fi.Close()
+	manifest, err := f.storage.Restore(fi)
+	if err != nil {
+		return utils.NewInternalError("failed to restore backup: " + err.Error())
+	}
+	f.printer.Printf("restored backup from %s (created at %s)\n", path, manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func (f *TerminalFeed) Sync() error {
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	if config.SyncRepo == "" {
+		return utils.NewInternalError("sync repository is not set, set it with: cleed config --sync-repo <path>")
+	}
+	res, err := f.storage.Sync(config.SyncRepo)
 	if err != nil {
-		return utils.NewInternalError("failed to get config path: " + err.Error())
+		return utils.NewInternalError("failed to sync: " + err.Error())
 	}
-	f.printer.Println(path)
+	if res.Committed {
+		f.printer.Println("committed local changes")
+	}
+	if res.Merged {
+		f.printer.Println("merged changes from", res.Upstream)
+	}
+	for i := range res.Conflicts {
+		f.printer.Println("resolved conflict in", res.Conflicts[i])
+	}
+	for i := range res.SettingsConflicts {
+		f.printer.Print(f.printer.ColorForeground("setting "+res.SettingsConflicts[i]+" was changed on both sides, kept the local value\n", 11))
+	}
+	if res.Pushed {
+		f.printer.Println("pushed changes")
+	}
+	f.printer.Printf("synced %s with %s\n", utils.Pluralize(int64(res.Lists), "list"), config.SyncRepo)
 	return nil
 }
 
-func (f *TerminalFeed) ShowCachePath() error {
-	path, err := f.storage.JoinCacheDir("")
+func (f *TerminalFeed) MarkRead(items []string, list string, read bool) error {
+	feeds, err := f.loadFeeds(list)
 	if err != nil {
-		return utils.NewInternalError("failed to get cache path: " + err.Error())
+		return err
 	}
-	f.printer.Println(path)
-	return nil
-}
-
-func (f *TerminalFeed) ShowCacheInfo() error {
-	cacheInfo, err := f.storage.LoadCacheInfo()
+	urls := make([]string, 0, len(feeds))
+	for url := range feeds {
+		urls = append(urls, url)
+	}
+	results, err := f.storage.MarkItems(urls, items, read)
 	if err != nil {
-		return utils.NewInternalError("failed to load cache info: " + err.Error())
+		return utils.NewInternalError("failed to mark items: " + err.Error())
 	}
-	cellMax := [1]int{}
-	items := make([]*storage.CacheInfoItem, 0, len(cacheInfo))
-	for k, v := range cacheInfo {
-		cellMax[0] = max(cellMax[0], len(k))
-		items = append(items, v)
+	state := "read"
+	if !read {
+		state = "unread"
 	}
-	f.printer.Print(runewidth.FillRight("URL", cellMax[0]))
-	f.printer.Println("  Last fetch           Fetch after")
-	slices.SortFunc(items, func(a, b *storage.CacheInfoItem) int {
-		if a.URL < b.URL {
-			return -1
-		}
-		if a.URL > b.URL {
-			return 1
+	if len(items) == 0 {
+		if list == "" {
+			f.printer.Printf("marked all items as %s\n", state)
+		} else {
+			f.printer.Printf("marked all items in list %s as %s\n", list, state)
 		}
-		return 0
-	})
+		return nil
+	}
 	for i := range items {
-		f.printer.Print(runewidth.FillRight(items[i].URL, cellMax[0]))
-		f.printer.Printf("  %s  %s\n", items[i].LastFetch.Format("2006-01-02 15:04:05"), items[i].FetchAfter.Format("2006-01-02 15:04:05"))
+		if results[i] {
+			f.printer.Printf("%s was marked as %s\n", items[i], state)
+		} else {
+			f.printer.Print(f.printer.ColorForeground(items[i]+" was not found\n", 11))
+		}
 	}
 	return nil
 }
 
 type FeedOptions struct {
-	List  string
-	Query [][]rune
-	Limit int
-	Since time.Time
+	List    string
+	Query   [][]rune
+	Limit   int
+	Since   time.Time
+	Unread  bool
+	Tags    []string // only feeds with any of the tags
+	NotTags []string // no feeds with any of the tags
+	Output  string   // structured output format, e.g. json; colored text if empty
+	FeedURL string   // URL the aggregated feed is published at, e.g. for the Atom self link
+
+	Template *template.Template // executed for each item instead of the default output
 }
 
 func (f *TerminalFeed) Search(query string, opts *FeedOptions) error {
@@ -425,25 +1443,18 @@ func (f *TerminalFeed) Search(query string, opts *FeedOptions) error {
 	if err != nil {
 		return err
 	}
-	slices.SortFunc(items, func(a, b *FeedItem) int {
-		if a.Score > b.Score {
-			return 1
-		}
-		if a.Score < b.Score {
-			return -1
-		}
-		return 0
-	})
-	f.outputItems(items, config, summary, opts)
-	return nil
+	sortByScore(items)
+	return f.outputItems(items, config, summary, opts)
 }
 
 type FeedItem struct {
 	Feed              *gofeed.Feed
 	Item              *gofeed.Item
+	FeedURL           string
 	PublishedRelative string
 	FeedColor         uint8
 	IsNew             bool
+	IsRead            bool
 	Score             int
 }
 
@@ -468,6 +1479,246 @@ func (f *TerminalFeed) Feed(opts *FeedOptions) error {
 	if err != nil {
 		return err
 	}
+	sortByPublished(items)
+	config.LastRun = f.time.Now()
+	f.storage.SaveConfig()
+	return f.outputItems(items, config, summary, opts)
+}
+
+// Star adds items to the starred items. Items are looked up by ID or link in
+// the item states of the followed feeds, and then in the feed they belong to.
+func (f *TerminalFeed) Star(items []string) error {
+	feeds, err := f.loadFeeds("")
+	if err != nil {
+		return err
+	}
+	itemStates, err := f.storage.LoadItemStates()
+	if err != nil {
+		return utils.NewInternalError("failed to load item states: " + err.Error())
+	}
+	found := make([]*storage.StarredItem, len(items))
+	for url, states := range itemStates {
+		listItem, ok := feeds[url]
+		if !ok {
+			continue
+		}
+		var feed *gofeed.Feed
+		for i := range items {
+			if found[i] != nil || !hasItemState(states, items[i]) {
+				continue
+			}
+			if feed == nil {
+				feed = f.loadStarFeed(url, listItem)
+			}
+			for _, item := range feed.Items {
+				if items[i] == itemID(item) || items[i] == item.Link {
+					found[i] = f.newStarredItem(url, feed, item)
+					break
+				}
+			}
+		}
+	}
+	starred := make([]*storage.StarredItem, 0, len(found))
+	for i := range found {
+		if found[i] != nil {
+			starred = append(starred, found[i])
+		}
+	}
+	err = f.storage.AddStarred(starred)
+	if err != nil {
+		return utils.NewInternalError("failed to save starred items: " + err.Error())
+	}
+	for i := range items {
+		if found[i] != nil {
+			f.printer.Print(items[i] + " was starred\n")
+		} else {
+			f.printer.Print(f.printer.ColorForeground(items[i]+" was not found\n", 11))
+		}
+	}
+	return nil
+}
+
+func hasItemState(states map[string]*storage.ItemState, item string) bool {
+	if _, ok := states[item]; ok {
+		return true
+	}
+	for _, state := range states {
+		if state.Link == item {
+			return true
+		}
+	}
+	return false
+}
+
+// loadStarFeed returns a feed with the items of its snapshot and archive, and
+// the title of the list metadata if set.
+func (f *TerminalFeed) loadStarFeed(url string, listItem *storage.ListItem) *gofeed.Feed {
+	feed, err := f.storage.LoadFeedSnapshot(url)
+	if err != nil {
+		feed, err = f.parseFeed(url)
+		if err != nil {
+			feed = &gofeed.Feed{}
+		}
+	}
+	// The archive also has the items that are no longer in the feed
+	archived, err := f.storage.LoadFeedArchive(url)
+	if err != nil {
+		f.printer.ErrPrintf("failed to load feed archive: %s: %v\n", url, err)
+	}
+	feed.Items = append(feed.Items, archived...)
+	if title := listItem.Meta.Get(storage.ListItemTitle); title != "" {
+		feed.Title = title
+	}
+	return feed
+}
+
+func (f *TerminalFeed) Unstar(items []string) error {
+	results, err := f.storage.RemoveStarred(items)
+	if err != nil {
+		return utils.NewInternalError("failed to remove starred items: " + err.Error())
+	}
+	for i := range items {
+		if results[i] {
+			f.printer.Print(items[i] + " was unstarred\n")
+		} else {
+			f.printer.Print(f.printer.ColorForeground(items[i]+" was not found in the starred items\n", 11))
+		}
+	}
+	return nil
+}
+
+// Starred prints the starred items. The items can be filtered by query, list,
+// tags and read state like the items of the feeds.
+func (f *TerminalFeed) Starred(query string, opts *FeedOptions) error {
+	summary := &RunSummary{
+		Start: f.time.Now(),
+	}
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	if query != "" {
+		opts.Query = utils.Tokenize(query, nil)
+		if len(opts.Query) == 0 {
+			return utils.NewInternalError("query is empty")
+		}
+	}
+	// Starred items of feeds that are no longer followed are only shown
+	// when the items are not filtered by list or tags
+	filtered := opts.List != "" || len(opts.Tags) > 0 || len(opts.NotTags) > 0
+	lists, err := f.storage.LoadLists()
+	if err != nil {
+		return utils.NewInternalError("failed to load lists: " + err.Error())
+	}
+	feeds := make(map[string]*storage.ListItem)
+	if filtered || len(lists) > 0 {
+		feeds, err = f.loadFeeds(opts.List)
+		if err != nil {
+			return err
+		}
+		filterFeedsByTags(feeds, opts.Tags, opts.NotTags)
+	}
+	starred, err := f.storage.LoadStarred()
+	if err != nil {
+		return utils.NewInternalError("failed to load starred items: " + err.Error())
+	}
+	itemStates, err := f.storage.LoadItemStates()
+	if err != nil {
+		return utils.NewInternalError("failed to load item states: " + err.Error())
+	}
+	items := make([]*FeedItem, 0, len(starred))
+	seen := make(map[string]struct{})
+	for _, s := range starred {
+		listItem, ok := feeds[s.FeedURL]
+		if !ok && filtered {
+			continue
+		}
+		var meta url.Values
+		feedTitle := s.FeedTitle
+		if listItem != nil {
+			meta = listItem.Meta
+			if title := meta.Get(storage.ListItemTitle); title != "" {
+				feedTitle = title
+			}
+		}
+		item := &gofeed.Item{
+			GUID:            s.ID,
+			Title:           s.Title,
+			Link:            s.Link,
+			Content:         s.Content,
+			PublishedParsed: s.Published,
+			UpdatedParsed:   s.Updated,
+		}
+		if item.PublishedParsed == nil {
+			item.PublishedParsed = &time.Time{}
+		}
+		if !opts.Since.IsZero() && item.PublishedParsed.Before(opts.Since) {
+			continue
+		}
+		state := itemStates[s.FeedURL][s.ID]
+		read := state != nil && state.Read
+		if opts.Unread && read {
+			continue
+		}
+		score := 0
+		if len(opts.Query) > 0 {
+			score = utils.Score(opts.Query, f.tokenizeItem(item))
+		}
+		if score == -1 {
+			continue
+		}
+		seen[s.FeedURL] = struct{}{}
+		summary.ItemsCount++
+		items = append(items, &FeedItem{
+			Feed:      &gofeed.Feed{Title: feedTitle},
+			Item:      item,
+			FeedURL:   s.FeedURL,
+			FeedColor: feedColor(s.FeedURL, meta, config),
+			IsRead:    read,
+			Score:     score,
+		})
+	}
+	summary.FeedsCount = len(seen)
+	summary.FeedsCached = len(seen)
+	if len(opts.Query) > 0 {
+		sortByScore(items)
+	} else {
+		sortByPublished(items)
+	}
+	return f.outputItems(items, config, summary, opts)
+}
+
+func (f *TerminalFeed) newStarredItem(url string, feed *gofeed.Feed, item *gofeed.Item) *storage.StarredItem {
+	content := item.Content
+	if content == "" {
+		content = item.Description
+	}
+	return &storage.StarredItem{
+		ID:        itemID(item),
+		FeedURL:   url,
+		FeedTitle: feed.Title,
+		Title:     item.Title,
+		Link:      item.Link,
+		Published: item.PublishedParsed,
+		Updated:   item.UpdatedParsed,
+		Content:   content,
+		StarredAt: f.time.Now(),
+	}
+}
+
+func sortByScore(items []*FeedItem) {
+	slices.SortFunc(items, func(a, b *FeedItem) int {
+		if a.Score > b.Score {
+			return 1
+		}
+		if a.Score < b.Score {
+			return -1
+		}
+		return 0
+	})
+}
+
+func sortByPublished(items []*FeedItem) {
 	slices.SortFunc(items, func(a, b *FeedItem) int {
 		if a.Item.PublishedParsed == nil || b.Item.PublishedParsed == nil {
 			return 0
@@ -480,10 +1731,6 @@ func (f *TerminalFeed) Feed(opts *FeedOptions) error {
 		}
 		return 0
 	})
-	config.LastRun = f.time.Now()
-	f.storage.SaveConfig()
-	f.outputItems(items, config, summary, opts)
-	return nil
 }
 
 func (f *TerminalFeed) outputItems(
@@ -491,21 +1738,39 @@ func (f *TerminalFeed) outputItems(
 	config *storage.Config,
 	summary *RunSummary,
 	opts *FeedOptions,
-) {
+) error {
 	l := len(items)
-	if l == 0 {
-		f.printer.ErrPrintln("no items to display")
-		return
-	}
 	if opts.Limit > 0 {
 		l = min(len(items), opts.Limit)
 	}
+	if opts.Output != "" {
+		err := f.outputRecords(items[:l], opts)
+		if err != nil {
+			return utils.NewInternalError("failed to write items: " + err.Error())
+		}
+		return nil
+	}
+	if l == 0 {
+		f.printer.ErrPrintln("no items to display")
+		return nil
+	}
 	cellMax := [1]int{}
 	for i := l - 1; i >= 0; i-- {
 		fi := items[i]
 		fi.PublishedRelative = utils.Relative(f.time.Now().Unix() - fi.Item.PublishedParsed.Unix())
 		cellMax[0] = max(cellMax[0], runewidth.StringWidth(fi.Feed.Title), len(fi.PublishedRelative))
 	}
+	if opts.Template != nil {
+		err := f.outputTemplate(items[:l], opts.Template)
+		if err != nil {
+			return utils.NewInternalError("failed to execute template: " + err.Error())
+		}
+		if config.Summary == 1 {
+			summary.ItemsShown = l
+			f.printSummary(summary)
+		}
+		return nil
+	}
 	cellMax[0] = min(cellMax[0], 30)
 	secondaryTextColor := mapColor(7, config)
 	highlightColor := mapColor(10, config)
@@ -530,6 +1795,7 @@ func (f *TerminalFeed) outputItems(
 		summary.ItemsShown = l
 		f.printSummary(summary)
 	}
+	return nil
 }
 
 func (f *TerminalFeed) printSummary(s *RunSummary) {
@@ -543,11 +1809,14 @@ func (f *TerminalFeed) printSummary(s *RunSummary) {
 	)
 }
 
-func (f *TerminalFeed) processFeeds(opts *FeedOptions, config *storage.Config, summary *RunSummary) ([]*FeedItem, error) {
+func (f *TerminalFeed) loadFeeds(list string) (map[string]*storage.ListItem, error) {
 	var err error
 	lists := make([]string, 0)
-	if opts.List != "" {
-		lists = append(lists, opts.List)
+	if list != "" {
+		lists, err = f.storage.LoadListTree(list)
+		if err != nil {
+			return nil, utils.NewInternalError("failed to load lists: " + err.Error())
+		}
 	} else {
 		lists, err = f.storage.LoadLists()
 		if err != nil {
@@ -559,17 +1828,42 @@ func (f *TerminalFeed) processFeeds(opts *FeedOptions, config *storage.Config, s
 	}
 	feeds := make(map[string]*storage.ListItem)
 	for i := range lists {
-		f.storage.LoadFeedsFromList(feeds, lists[i])
+		m := make(map[string]*storage.ListItem)
+		f.storage.LoadFeedsFromList(m, lists[i])
+		for address, item := range m {
+			// A feed in several lists has the tags of all of them
+			if prev, ok := feeds[address]; ok && prev.Meta.Has(storage.ListItemTags) {
+				tags := utils.ParseTags(prev.Meta.Get(storage.ListItemTags) + "," + item.Meta.Get(storage.ListItemTags))
+				if item.Meta == nil {
+					item.Meta = make(url.Values)
+				}
+				item.Meta.Set(storage.ListItemTags, strings.Join(tags, ","))
+			}
+			feeds[address] = item
+		}
+	}
+	return feeds, nil
+}
+
+func (f *TerminalFeed) processFeeds(opts *FeedOptions, config *storage.Config, summary *RunSummary) ([]*FeedItem, error) {
+	f.refreshRemoteLists(opts.List, config)
+	feeds, err := f.loadFeeds(opts.List)
+	if err != nil {
+		return nil, err
 	}
+	filterFeedsByTags(feeds, opts.Tags, opts.NotTags)
 	summary.FeedsCount = len(feeds)
 	cacheInfo, err := f.storage.LoadCacheInfo()
 	if err != nil {
 		return nil, utils.NewInternalError("failed to load cache info: " + err.Error())
 	}
+	itemStates, err := f.storage.LoadItemStates()
+	if err != nil {
+		return nil, utils.NewInternalError("failed to load item states: " + err.Error())
+	}
 	mx := sync.Mutex{}
 	wg := sync.WaitGroup{}
 	items := make([]*FeedItem, 0)
-	feedColorMap := make(map[string]uint8)
 	for url := range feeds {
 		ci := cacheInfo[url]
 		if ci == nil {
@@ -588,19 +1882,21 @@ func (f *TerminalFeed) processFeeds(opts *FeedOptions, config *storage.Config, s
 				f.printer.ErrPrintf("failed to fetch feed: %s: %v\n", ci.URL, err)
 				return
 			}
-			feed, err := f.parseFeed(url)
+			feed, err := f.loadFeed(ci.URL, res.Changed)
 			if err != nil {
 				f.printer.ErrPrintf("failed to parse feed: %s: %v\n", ci.URL, err)
 				return
 			}
+			feed.Items = f.mergeArchive(ci.URL, feed.Items, res.Changed, config)
+			meta := feeds[ci.URL].Meta
+			if title := meta.Get(storage.ListItemTitle); title != "" {
+				feed.Title = title
+			}
 			mx.Lock()
 			defer mx.Unlock()
 			summary.ItemsCount += len(feed.Items)
-			color, ok := feedColorMap[feed.Title]
-			if !ok {
-				color = mapColor(uint8(len(feedColorMap)%256), config)
-				feedColorMap[feed.Title] = color
-			}
+			color := feedColor(ci.URL, meta, config)
+			states, created := f.updateItemStates(itemStates, ci.URL, feed.Items)
 			for _, feedItem := range feed.Items {
 				if feedItem.PublishedParsed == nil {
 					feedItem.PublishedParsed = &time.Time{}
@@ -608,6 +1904,11 @@ func (f *TerminalFeed) processFeeds(opts *FeedOptions, config *storage.Config, s
 				if !opts.Since.IsZero() && feedItem.PublishedParsed.Before(opts.Since) {
 					continue
 				}
+				id := itemID(feedItem)
+				state := states[id]
+				if opts.Unread && state.Read {
+					continue
+				}
 				score := 0
 				if len(opts.Query) > 0 {
 					score = utils.Score(opts.Query, f.tokenizeItem(feedItem))
@@ -618,8 +1919,10 @@ func (f *TerminalFeed) processFeeds(opts *FeedOptions, config *storage.Config, s
 				items = append(items, &FeedItem{
 					Feed:      feed,
 					Item:      feedItem,
+					FeedURL:   ci.URL,
 					FeedColor: color,
-					IsNew:     feedItem.PublishedParsed.After(ci.LastFetch),
+					IsNew:     isNewItem(state, created[id], ci.LastFetch),
+					IsRead:    state.Read,
 					Score:     score,
 				})
 			}
@@ -640,9 +1943,139 @@ func (f *TerminalFeed) processFeeds(opts *FeedOptions, config *storage.Config, s
 	if err != nil {
 		f.printer.ErrPrintln("failed to save cache informaton:", err)
 	}
+	err = f.storage.SaveItemStates(itemStates)
+	if err != nil {
+		f.printer.ErrPrintln("failed to save item states:", err)
+	}
 	return items, nil
 }
 
+// filterFeedsByTags removes the feeds that have none of the tags, if any are
+// given, and the feeds that have any of the excluded tags.
+func filterFeedsByTags(feeds map[string]*storage.ListItem, tags, notTags []string) {
+	if len(tags) == 0 && len(notTags) == 0 {
+		return
+	}
+	tags = utils.ParseTags(strings.Join(tags, ","))
+	notTags = utils.ParseTags(strings.Join(notTags, ","))
+	for url, feed := range feeds {
+		feedTags := feed.Tags()
+		hasTag := func(tag string) bool {
+			return slices.Contains(feedTags, tag)
+		}
+		if len(tags) > 0 && !slices.ContainsFunc(tags, hasTag) {
+			delete(feeds, url)
+			continue
+		}
+		if slices.ContainsFunc(notTags, hasTag) {
+			delete(feeds, url)
+		}
+	}
+}
+
+// mergeArchive returns the feed items together with the archived items that are
+// no longer in the feed, within the retention limits. The archive is saved if
+// the feed changed or items were added or removed.
+func (f *TerminalFeed) mergeArchive(
+	url string,
+	items []*gofeed.Item,
+	changed bool,
+	config *storage.Config,
+) []*gofeed.Item {
+	archived, err := f.storage.LoadFeedArchive(url)
+	if err != nil {
+		f.printer.ErrPrintf("failed to load feed archive: %s: %v\n", url, err)
+	}
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		seen[itemID(item)] = struct{}{}
+	}
+	older := make([]*gofeed.Item, 0, len(archived))
+	for _, item := range archived {
+		if _, ok := seen[itemID(item)]; !ok {
+			older = append(older, item)
+		}
+	}
+	slices.SortStableFunc(older, func(a, b *gofeed.Item) int {
+		return publishedTime(b).Compare(publishedTime(a))
+	})
+	if config.ArchiveMaxAge != "" {
+		maxAge, err := utils.ParseDuration(config.ArchiveMaxAge)
+		if err == nil {
+			after := f.time.Now().Add(-maxAge)
+			i := slices.IndexFunc(older, func(item *gofeed.Item) bool {
+				return publishedTime(item).Before(after)
+			})
+			if i != -1 {
+				older = older[:i]
+			}
+		}
+	}
+	if config.ArchiveMaxItems > 0 {
+		older = older[:min(len(older), max(int(config.ArchiveMaxItems)-len(items), 0))]
+	}
+	merged := append(items, older...)
+	if changed || len(merged) != len(archived) {
+		err = f.storage.SaveFeedArchive(url, merged)
+		if err != nil {
+			f.printer.ErrPrintf("failed to save feed archive: %s: %v\n", url, err)
+		}
+	}
+	return merged
+}
+
+func publishedTime(item *gofeed.Item) time.Time {
+	if item.PublishedParsed == nil {
+		return time.Time{}
+	}
+	return *item.PublishedParsed
+}
+
+// updateItemStates records the items that are seen for the first time and
+// drops the states of the items that are no longer in the feed. It returns the
+// states of the items and the IDs of the items that are seen for the first
+// time.
+func (f *TerminalFeed) updateItemStates(
+	itemStates map[string]map[string]*storage.ItemState,
+	url string,
+	items []*gofeed.Item,
+) (map[string]*storage.ItemState, map[string]bool) {
+	prev := itemStates[url]
+	states := make(map[string]*storage.ItemState, len(items))
+	created := make(map[string]bool)
+	for _, item := range items {
+		id := itemID(item)
+		state, ok := prev[id]
+		if !ok {
+			state = &storage.ItemState{
+				Link:      item.Link,
+				FirstSeen: f.time.Now(),
+			}
+			created[id] = true
+		}
+		states[id] = state
+	}
+	itemStates[url] = states
+	return states, created
+}
+
+// isNewItem reports whether an item was first seen in this run or after the
+// previous fetch of its feed. Items published in the past that only appear in
+// the feed later are new as well.
+func isNewItem(state *storage.ItemState, created bool, lastFetch time.Time) bool {
+	return created || state.FirstSeen.After(lastFetch)
+}
+
+func itemID(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+	return item.Title
+}
+
 func (f *TerminalFeed) tokenizeItem(item *gofeed.Item) [][]rune {
 	tokens := utils.Tokenize(item.Title, nil)
 	for i := range item.Categories {
@@ -651,6 +2084,26 @@ func (f *TerminalFeed) tokenizeItem(item *gofeed.Item) [][]rune {
 	return tokens
 }
 
+// loadFeed returns the snapshot of the parsed feed if the feed did not change.
+// Otherwise, it parses the cached feed and saves a new snapshot.
+func (f *TerminalFeed) loadFeed(url string, changed bool) (*gofeed.Feed, error) {
+	if !changed {
+		feed, err := f.storage.LoadFeedSnapshot(url)
+		if err == nil {
+			return feed, nil
+		}
+	}
+	feed, err := f.parseFeed(url)
+	if err != nil {
+		return nil, err
+	}
+	err = f.storage.SaveFeedSnapshot(url, feed)
+	if err != nil {
+		f.printer.ErrPrintf("failed to save feed snapshot: %s: %v\n", url, err)
+	}
+	return feed, nil
+}
+
 func (f *TerminalFeed) parseFeed(url string) (*gofeed.Feed, error) {
 	fc, err := f.storage.OpenFeedCache(url)
 	if err != nil {
@@ -667,6 +2120,13 @@ type FetchResult struct {
 }
 
 func (f *TerminalFeed) fetchFeed(feed *storage.CacheInfoItem) (*FetchResult, error) {
+	return f.fetch(feed, "application/rss+xml, application/atom+xml, application/xml, application/json, text/xml")
+}
+
+// fetch downloads a feed or an OPML to the feed cache. It is skipped before
+// FetchAfter and sends the ETag and the time of the last fetch, so unchanged
+// files are not downloaded again.
+func (f *TerminalFeed) fetch(feed *storage.CacheInfoItem, accept string) (*FetchResult, error) {
 	if feed.FetchAfter.After(f.time.Now()) {
 		return &FetchResult{
 			Changed: false,
@@ -685,7 +2145,7 @@ func (f *TerminalFeed) fetchFeed(feed *storage.CacheInfoItem) (*FetchResult, err
 	if !feed.LastFetch.IsZero() {
 		req.Header.Set("If-Modified-Since", feed.LastFetch.Format(http.TimeFormat))
 	}
-	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, application/json, text/xml")
+	req.Header.Set("Accept", accept)
 	req.Header.Set("Accept-Encoding", "br, gzip")
 	res, err := f.http.Do(req)
 	if err != nil {
@@ -758,6 +2218,28 @@ func parseMaxAge(cacheControl string) time.Duration {
 	return 60 * time.Second
 }
 
+var defaultPalette = []uint8{1, 2, 3, 4, 5, 6, 9, 10, 11, 12, 13, 14}
+
+func palette(config *storage.Config) []uint8 {
+	if len(config.Palette) == 0 {
+		return defaultPalette
+	}
+	return config.Palette
+}
+
+// feedColor returns the color of a feed. The color set in the list metadata is
+// used as is, otherwise the color is picked from the palette by the hash of
+// the feed URL, so it is the same between runs.
+func feedColor(url string, meta url.Values, config *storage.Config) uint8 {
+	if c, err := strconv.ParseUint(meta.Get(storage.ListItemColor), 10, 8); err == nil {
+		return uint8(c)
+	}
+	p := palette(config)
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	return mapColor(p[h.Sum32()%uint32(len(p))], config)
+}
+
 func mapColor(color uint8, config *storage.Config) uint8 {
 	if c, ok := config.ColorMap[color]; ok {
 		return c
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/internal/utils"
+)
+
+const benchFeedsCount = 300
+
+func createBenchRSS(feed int) string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, `<rss version="2.0"><channel><title>Feed %d</title><link>https://feed-%d.com/</link>`, feed, feed)
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(b, `<item><title>Item %d</title><link>https://feed-%d.com/item-%d/</link><guid>%d-%d</guid>`+
+			`<pubDate>%s</pubDate><category>category</category><description>%s</description></item>`,
+			i, feed, i, feed, i,
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(i)*time.Hour).Format(time.RFC1123Z),
+			strings.Repeat("Lorem ipsum dolor sit amet. ", 20),
+		)
+	}
+	b.WriteString("</channel></rss>")
+	return b.String()
+}
+
+func setupBenchFeeds(b *testing.B) (*TerminalFeed, *storage.LocalStorage) {
+	s := storage.NewLocalStorage("cleed_bench", utils.NewTime())
+	b.Cleanup(func() {
+		s.ClearAll()
+	})
+	err := s.Init("bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	cacheInfo := make(map[string]*storage.CacheInfoItem)
+	urls := make([]string, benchFeedsCount)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://feed-%d.com/rss", i)
+		err = s.SaveFeedCache(bytes.NewBufferString(createBenchRSS(i)), urls[i])
+		if err != nil {
+			b.Fatal(err)
+		}
+		cacheInfo[urls[i]] = &storage.CacheInfoItem{
+			URL:        urls[i],
+			LastFetch:  time.Now(),
+			FetchAfter: time.Now().Add(time.Hour),
+		}
+	}
+	err = s.SaveCacheInfo(cacheInfo)
+	if err != nil {
+		b.Fatal(err)
+	}
+	_, err = s.AddToList(urls, "default")
+	if err != nil {
+		b.Fatal(err)
+	}
+	printer := NewPrinter(nil, io.Discard, io.Discard)
+	return NewTerminalFeed(utils.NewTime(), printer, s), s
+}
+
+func runBenchProcessFeeds(b *testing.B, f *TerminalFeed, s *storage.LocalStorage) {
+	config, err := s.LoadConfig()
+	if err != nil {
+		b.Fatal(err)
+	}
+	items, err := f.processFeeds(&FeedOptions{}, config, &RunSummary{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if len(items) != benchFeedsCount*20 {
+		b.Fatalf("expected %d items, got %d", benchFeedsCount*20, len(items))
+	}
+}
+
+func Benchmark_ProcessFeeds_Parse(b *testing.B) {
+	f, s := setupBenchFeeds(b)
+	runBenchProcessFeeds(b, f, s)
+	cacheDir, err := s.JoinCacheDir("")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for j := 0; j < benchFeedsCount; j++ {
+			os.Remove(path.Join(cacheDir, "parsed_"+url.QueryEscape(fmt.Sprintf("https://feed-%d.com/rss", j))))
+		}
+		b.StartTimer()
+		runBenchProcessFeeds(b, f, s)
+	}
+}
+
+func Benchmark_ProcessFeeds_Snapshot(b *testing.B) {
+	f, s := setupBenchFeeds(b)
+	runBenchProcessFeeds(b, f, s)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runBenchProcessFeeds(b, f, s)
+	}
+}
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Formats of the structured output of items
+const (
+	OutputJSON   = "json"
+	OutputNDJSON = "ndjson"
+	OutputCSV    = "csv"
+	OutputTSV    = "tsv"
+
+	// A single feed with the items of all feeds
+	OutputAtom     = "atom"
+	OutputRSS      = "rss"
+	OutputJSONFeed = "jsonfeed"
+)
+
+var OutputFormats = []string{
+	OutputJSON,
+	OutputNDJSON,
+	OutputCSV,
+	OutputTSV,
+	OutputAtom,
+	OutputRSS,
+	OutputJSONFeed,
+}
+
+// ItemRecord is an item in the structured output.
+type ItemRecord struct {
+	FeedTitle  string     `json:"feedTitle"`
+	FeedURL    string     `json:"feedUrl"`
+	Title      string     `json:"title"`
+	Link       string     `json:"link"`
+	GUID       string     `json:"guid"`
+	Published  *time.Time `json:"published"`
+	Updated    *time.Time `json:"updated"`
+	Categories []string   `json:"categories"`
+	Authors    []string   `json:"authors"`
+	IsNew      bool       `json:"isNew"`
+	IsRead     bool       `json:"isRead"`
+	Score      int        `json:"score"`
+}
+
+var itemRecordHeader = []string{
+	"feedTitle",
+	"feedUrl",
+	"title",
+	"link",
+	"guid",
+	"published",
+	"updated",
+	"categories",
+	"authors",
+	"isNew",
+	"isRead",
+	"score",
+}
+
+func newItemRecord(fi *FeedItem) *ItemRecord {
+	r := &ItemRecord{
+		FeedTitle:  fi.Feed.Title,
+		FeedURL:    fi.FeedURL,
+		Title:      fi.Item.Title,
+		Link:       fi.Item.Link,
+		GUID:       fi.Item.GUID,
+		Published:  recordTime(fi.Item.PublishedParsed),
+		Updated:    recordTime(fi.Item.UpdatedParsed),
+		Categories: fi.Item.Categories,
+		Authors:    make([]string, 0, len(fi.Item.Authors)),
+		IsNew:      fi.IsNew,
+		IsRead:     fi.IsRead,
+		Score:      fi.Score,
+	}
+	if r.Categories == nil {
+		r.Categories = []string{}
+	}
+	for _, a := range itemAuthors(fi.Item) {
+		r.Authors = append(r.Authors, formatPerson(a))
+	}
+	return r
+}
+
+func (r *ItemRecord) fields() []string {
+	return []string{
+		r.FeedTitle,
+		r.FeedURL,
+		r.Title,
+		r.Link,
+		r.GUID,
+		formatRecordTime(r.Published),
+		formatRecordTime(r.Updated),
+		strings.Join(r.Categories, ","),
+		strings.Join(r.Authors, ","),
+		strconv.FormatBool(r.IsNew),
+		strconv.FormatBool(r.IsRead),
+		strconv.Itoa(r.Score),
+	}
+}
+
+// recordTime returns nil for missing dates, which are set to the zero time
+// while processing the feeds.
+func recordTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func formatRecordTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatPerson(p *gofeed.Person) string {
+	if p.Email == "" {
+		return p.Name
+	}
+	if p.Name == "" {
+		return p.Email
+	}
+	return p.Name + " <" + p.Email + ">"
+}
+
+// outputRecords writes the items as JSON, NDJSON, CSV or TSV records or as an
+// aggregated feed.
+func (f *TerminalFeed) outputRecords(items []*FeedItem, opts *FeedOptions) error {
+	format := opts.Output
+	switch format {
+	case OutputAtom, OutputRSS, OutputJSONFeed:
+		return f.outputAggregatedFeed(items, opts)
+	}
+	records := make([]*ItemRecord, len(items))
+	for i := range items {
+		records[i] = newItemRecord(items[i])
+	}
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(f.printer.OutWriter)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case OutputNDJSON:
+		enc := json.NewEncoder(f.printer.OutWriter)
+		enc.SetEscapeHTML(false)
+		for i := range records {
+			err := enc.Encode(records[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputCSV:
+		w := csv.NewWriter(f.printer.OutWriter)
+		w.Write(itemRecordHeader)
+		for i := range records {
+			w.Write(records[i].fields())
+		}
+		w.Flush()
+		return w.Error()
+	case OutputTSV:
+		// Tabs and line breaks cannot be escaped in TSV, so they are replaced
+		replacer := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+		f.printer.Print(strings.Join(itemRecordHeader, "\t"), "\n")
+		for i := range records {
+			fields := records[i].fields()
+			for j := range fields {
+				fields[j] = replacer.Replace(fields[j])
+			}
+			f.printer.Print(strings.Join(fields, "\t"), "\n")
+		}
+		return nil
+	}
+	return nil
+}
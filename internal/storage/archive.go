@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	// Archives are kept in the config directory, so clearing the cache does
+	// not remove the items that are no longer in the feeds.
+	archiveDir = "archive"
+
+	// Prefix of the archives kept in the cache directory by older versions
+	archivePrefix = "archive_"
+)
+
+// LoadFeedArchive returns all the archived items of a feed.
+func (s *LocalStorage) LoadFeedArchive(name string) ([]*gofeed.Item, error) {
+	path, err := s.joinArchiveDir(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := openCompressedFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+	items := make([]*gofeed.Item, 0)
+	err = json.NewDecoder(r).Decode(&items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SaveFeedArchive stores the archived items gzip compressed.
+func (s *LocalStorage) SaveFeedArchive(name string, items []*gofeed.Item) error {
+	p, err := s.joinArchiveDir(name)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(path.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return writeFeedCacheFile(p, bytes.NewReader(b))
+}
+
+// RemoveFeedArchives removes the archived items of the given feeds.
+func (s *LocalStorage) RemoveFeedArchives(names []string) error {
+	for i := range names {
+		path, err := s.joinArchiveDir(names[i])
+		if err != nil {
+			return err
+		}
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveFeedArchives moves the archived items of the feeds to their new
+// addresses. Items that are already in the archive of the new address are
+// dropped.
+func (s *LocalStorage) MoveFeedArchives(replacements map[string]string) error {
+	for from, to := range replacements {
+		if from == to {
+			continue
+		}
+		moved, err := s.LoadFeedArchive(from)
+		if err != nil {
+			return err
+		}
+		if len(moved) == 0 {
+			continue
+		}
+		items, err := s.LoadFeedArchive(to)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]struct{}, len(items))
+		for _, item := range items {
+			seen[archivedItemKey(item)] = struct{}{}
+		}
+		for _, item := range moved {
+			if _, ok := seen[archivedItemKey(item)]; !ok {
+				items = append(items, item)
+			}
+		}
+		err = s.SaveFeedArchive(to, items)
+		if err != nil {
+			return err
+		}
+		err = s.RemoveFeedArchives([]string{from})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archivedItemKey identifies an archived item the same way the item states do.
+func archivedItemKey(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+	return item.Title
+}
+
+// joinArchiveDir returns the path of the archive of a feed, or of the archive
+// directory if name is empty.
+func (s *LocalStorage) joinArchiveDir(name string) (string, error) {
+	if name == "" {
+		return s.JoinConfigDir(archiveDir)
+	}
+	return s.JoinConfigDir(path.Join(archiveDir, url.QueryEscape(name)))
+}
@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	manifestFile = "manifest.json"
+
+	backupConfigDir = "config"
+	backupCacheDir  = "cache"
+)
+
+// BackupManifest is the first entry of a backup archive.
+type BackupManifest struct {
+	Version   string         `json:"version"`
+	Schema    map[string]int `json:"schema"`
+	Cache     bool           `json:"cache"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// Backup writes a gzipped tar archive with the config, lists, item states,
+// starred items, archived items and optionally the cache.
+func (s *LocalStorage) Backup(w io.Writer, includeCache bool) (*BackupManifest, error) {
+	config, err := s.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BackupManifest{
+		Version:   config.Version,
+		Schema:    config.Schema,
+		Cache:     includeCache,
+		CreatedAt: s.time.Now(),
+	}
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	err = writeTarEntry(tw, manifestFile, b, manifest.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{configFile, itemsFile, starredFile}
+	lists, err := s.LoadLists()
+	if err != nil {
+		return nil, err
+	}
+	for i := range lists {
+		files = append(files, path.Join(listsDir, listFileName(lists[i])))
+	}
+	dir, err := s.joinArchiveDir("")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, path.Join(archiveDir, entry.Name()))
+		}
+	}
+	for _, file := range files {
+		src, err := s.JoinConfigDir(file)
+		if err != nil {
+			return nil, err
+		}
+		err = writeTarFile(tw, path.Join(backupConfigDir, file), src)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if includeCache {
+		cacheDir, err := s.JoinCacheDir("")
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			err = writeTarFile(tw, path.Join(backupCacheDir, entry.Name()), path.Join(cacheDir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return manifest, zw.Close()
+}
+
+// Restore replaces the config, lists, item states, starred items, archived
+// items and, if the backup includes it, the cache with the content of a backup archive. The
+// restored files are migrated to the latest schema.
+//
+// The archive is extracted to staging directories first, so the current files
+// are only replaced once the whole archive has been read.
+func (s *LocalStorage) Restore(r io.Reader) (*BackupManifest, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup: %v", err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	header, err := tr.Next()
+	if err != nil || header.Name != manifestFile {
+		return nil, fmt.Errorf("invalid backup: missing %s", manifestFile)
+	}
+	manifest := &BackupManifest{}
+	err = json.NewDecoder(tr).Decode(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup: %v", err)
+	}
+	latest := LatestSchema()
+	for file, version := range manifest.Schema {
+		if version > latest[file] {
+			return nil, fmt.Errorf("%s schema version %d of the backup is newer than the supported version %d", file, version, latest[file])
+		}
+	}
+	configStage, err := s.makeStagingDir(s.JoinConfigDir)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(configStage)
+	cacheStage, err := s.makeStagingDir(s.JoinCacheDir)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(cacheStage)
+	// Staged file paths by their destination
+	staged := make(map[string]string)
+	for {
+		header, err = tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		dst, err := s.restorePath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		stage := configStage
+		if strings.HasPrefix(header.Name, backupCacheDir+"/") {
+			stage = cacheStage
+		}
+		name := path.Join(stage, fmt.Sprint(len(staged)))
+		err = writeFileFrom(name, tr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup: %v", err)
+		}
+		staged[dst] = name
+	}
+	err = s.clearBeforeRestore(manifest.Cache, path.Base(cacheStage))
+	if err != nil {
+		return nil, err
+	}
+	for dst, name := range staged {
+		err = os.Rename(name, dst)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.config = nil
+	return manifest, s.Migrate()
+}
+
+// makeStagingDir creates a temporary directory inside the config or cache
+// directory, so the staged files can be renamed to their destination.
+func (s *LocalStorage) makeStagingDir(join func(string) (string, error)) (string, error) {
+	dir, err := join("")
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(dir, ".restore-")
+}
+
+func (s *LocalStorage) clearBeforeRestore(cache bool, cacheStage string) error {
+	for _, file := range []string{itemsFile, starredFile} {
+		name, err := s.JoinConfigDir(file)
+		if err != nil {
+			return err
+		}
+		err = os.Remove(name)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	for _, name := range []string{listsDir, archiveDir} {
+		dir, err := s.JoinConfigDir(name)
+		if err != nil {
+			return err
+		}
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+	}
+	if cache {
+		dir, err := s.JoinCacheDir("")
+		if err != nil {
+			return err
+		}
+		return removeDirEntries(dir, profilesDir, cacheStage)
+	}
+	return nil
+}
+
+// restorePath returns where an archive entry is restored to. Only the files
+// written by Backup are accepted.
+func (s *LocalStorage) restorePath(name string) (string, error) {
+	dir, file := path.Split(name)
+	if file == "" || file == "." || file == ".." || strings.Contains(file, "\\") {
+		return "", fmt.Errorf("invalid backup entry: %s", name)
+	}
+	switch dir {
+	case backupConfigDir + "/":
+		if file == configFile || file == itemsFile || file == starredFile {
+			return s.JoinConfigDir(file)
+		}
+	case path.Join(backupConfigDir, listsDir) + "/":
+		return s.joinListsDir(listName(file))
+	case path.Join(backupConfigDir, archiveDir) + "/":
+		return s.JoinConfigDir(path.Join(archiveDir, file))
+	case backupCacheDir + "/":
+		return s.JoinCacheDir(file)
+	}
+	return "", fmt.Errorf("invalid backup entry: %s", name)
+}
+
+func writeTarEntry(tw *tar.Writer, name string, b []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(b)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(b)
+	return err
+}
+
+// writeTarFile adds a file to the archive. Missing files are skipped.
+func writeTarFile(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func writeFileFrom(name string, r io.Reader) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
@@ -2,19 +2,29 @@ package storage
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	cacheInfoFile = "cache_info"
+	cacheInfoFile   = "cache_info"
+	feedCachePrefix = "feed_"
 )
 
+// Files kept in the cache directory for each feed
+var feedCachePrefixes = []string{feedCachePrefix, snapshotPrefix}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
 type CacheInfoItem struct {
 	LastFetch  time.Time
 	FetchAfter time.Time
@@ -66,26 +76,99 @@ func (s *LocalStorage) SaveCacheInfo(cacheinfo map[string]*CacheInfoItem) error
 	return nil
 }
 
+// SaveFeedCache stores the feed body gzip compressed.
 func (s *LocalStorage) SaveFeedCache(r io.Reader, name string) error {
-	path, err := s.JoinCacheDir("feed_" + url.QueryEscape(name))
+	path, err := s.JoinCacheDir(feedCachePrefix + url.QueryEscape(name))
 	if err != nil {
 		return err
 	}
-	f, err := os.Create(path)
+	err = s.removeFeedSnapshot(name)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	_, err = io.Copy(f, r)
-	return err
+	return writeFeedCacheFile(path, r)
 }
 
+// OpenFeedCache returns the decompressed feed body. Caches saved by older
+// versions are not compressed, they are compressed the first time they are read.
 func (s *LocalStorage) OpenFeedCache(name string) (io.ReadCloser, error) {
-	path, err := s.JoinCacheDir("feed_" + url.QueryEscape(name))
+	path, err := s.JoinCacheDir(feedCachePrefix + url.QueryEscape(name))
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(len(gzipMagic))
+	if err == nil && bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &feedCacheReader{Reader: zr, f: f}, nil
+	}
+	b, err := io.ReadAll(br)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	// The content is still returned if the file cannot be rewritten, it will be
+	// compressed on the next fetch
+	writeFeedCacheFile(path, bytes.NewReader(b))
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+type feedCacheReader struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (r *feedCacheReader) Close() error {
+	err := r.Reader.Close()
+	if ferr := r.f.Close(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// openCompressedFile returns the decompressed content of a cache file. Files
+// saved by older versions are not compressed and are read as they are.
+func openCompressedFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
-	return os.Open(path)
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(len(gzipMagic))
+	if err == nil && bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &feedCacheReader{Reader: zr, f: f}, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, f}, nil
+}
+
+func writeFeedCacheFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := gzip.NewWriter(f)
+	_, err = io.Copy(zw, r)
+	if err != nil {
+		return err
+	}
+	return zw.Close()
 }
 
 func (s *LocalStorage) RemoveFeedCaches(names []string) error {
@@ -101,15 +184,150 @@ func (s *LocalStorage) RemoveFeedCaches(names []string) error {
 		return err
 	}
 	for i := range names {
-		path, err := s.JoinCacheDir("feed_" + url.QueryEscape(names[i]))
+		for _, prefix := range feedCachePrefixes {
+			path, err := s.JoinCacheDir(prefix + url.QueryEscape(names[i]))
+			if err != nil {
+				continue
+			}
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// FeedCacheSizes returns the on-disk size of the cache files of each feed.
+func (s *LocalStorage) FeedCacheSizes() (map[string]int64, error) {
+	dir, err := s.JoinCacheDir("")
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64)
+	for _, file := range files {
+		name, ok := feedFromCacheFile(file.Name())
+		if !ok || file.IsDir() {
+			continue
+		}
+		info, err := file.Info()
 		if err != nil {
+			return nil, err
+		}
+		sizes[name] += info.Size()
+	}
+	return sizes, nil
+}
+
+// PruneFeedCaches removes the caches and item states of the feeds that are
+// not in any list. The archives and the caches of remote OPMLs are kept. It returns the removed feeds.
+func (s *LocalStorage) PruneFeedCaches() ([]string, error) {
+	lists, err := s.LoadLists()
+	if err != nil {
+		return nil, err
+	}
+	feeds := make(map[string]*ListItem)
+	for i := range lists {
+		err = s.LoadFeedsFromList(feeds, lists[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The OPMLs of remote lists are cached like feeds
+	config, err := s.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, url := range config.RemoteLists {
+		feeds[url] = nil
+	}
+	cached := make(map[string]struct{})
+	cacheInfo, err := s.LoadCacheInfo()
+	if err != nil {
+		return nil, err
+	}
+	for name := range cacheInfo {
+		cached[name] = struct{}{}
+	}
+	sizes, err := s.FeedCacheSizes()
+	if err != nil {
+		return nil, err
+	}
+	for name := range sizes {
+		cached[name] = struct{}{}
+	}
+	states, err := s.LoadItemStates()
+	if err != nil {
+		return nil, err
+	}
+	for name := range states {
+		cached[name] = struct{}{}
+	}
+	names := make([]string, 0)
+	for name := range cached {
+		if _, ok := feeds[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return names, nil
+	}
+	err = s.RemoveFeedCaches(names)
+	if err != nil {
+		return nil, err
+	}
+	return names, s.RemoveItemStates(names)
+}
+
+// ClearCache removes all the files from the cache directory. The caches of
+// other profiles are kept.
+func (s *LocalStorage) ClearCache() error {
+	dir, err := s.JoinCacheDir("")
+	if err != nil {
+		return err
+	}
+	err = removeDirEntries(dir, profilesDir)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// removeDirEntries removes the entries of a directory, except the ones in keep.
+func removeDirEntries(dir string, keep ...string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if slices.Contains(keep, entry.Name()) {
 			continue
 		}
-		os.Remove(path)
+		err = os.RemoveAll(path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+func feedFromCacheFile(file string) (string, bool) {
+	for _, prefix := range feedCachePrefixes {
+		if strings.HasPrefix(file, prefix) {
+			name, err := url.QueryUnescape(file[len(prefix):])
+			if err != nil {
+				return "", false
+			}
+			return name, true
+		}
+	}
+	return "", false
+}
+
 func getCacheInfoItemLine(item *CacheInfoItem) []byte {
 	return []byte(fmt.Sprintf("%s %d %s %d\n",
 		item.URL,
@@ -121,7 +339,7 @@ func getCacheInfoItemLine(item *CacheInfoItem) []byte {
 
 func parseCacheInfoItem(line string) (*CacheInfoItem, error) {
 	parts := strings.Split(line, " ")
-	if len(parts) < 2 {
+	if len(parts) < 3 {
 		return nil, fmt.Errorf("invalid cache info line: %s", line)
 	}
 	lastCheck, err := strconv.ParseInt(parts[1], 10, 64)
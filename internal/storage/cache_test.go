@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FeedCache_Compressed(t *testing.T) {
+	s := newTestStorage(t)
+	err := s.Init("0.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir, err := s.JoinCacheDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := bytes.Repeat([]byte("<item><title>Item</title></item>"), 100)
+	err = s.SaveFeedCache(bytes.NewReader(body), "https://example.com")
+	assert.NoError(t, err)
+
+	b, err := os.ReadFile(path.Join(cacheDir, feedCachePrefix+url.QueryEscape("https://example.com")))
+	assert.NoError(t, err)
+	assert.Equal(t, gzipMagic, b[:2])
+	assert.Less(t, len(b), len(body))
+
+	fc, err := s.OpenFeedCache("https://example.com")
+	assert.NoError(t, err)
+	defer fc.Close()
+	b, err = io.ReadAll(fc)
+	assert.NoError(t, err)
+	assert.Equal(t, body, b)
+}
+
+func Test_FeedCache_Uncompressed(t *testing.T) {
+	s := newTestStorage(t)
+	err := s.Init("0.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir, err := s.JoinCacheDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := path.Join(cacheDir, feedCachePrefix+url.QueryEscape("https://example.com"))
+	writeFixture(t, name, "<rss></rss>")
+
+	fc, err := s.OpenFeedCache("https://example.com")
+	assert.NoError(t, err)
+	b, err := io.ReadAll(fc)
+	assert.NoError(t, err)
+	assert.Equal(t, "<rss></rss>", string(b))
+	fc.Close()
+
+	b, err = os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, gzipMagic, b[:2])
+
+	fc, err = s.OpenFeedCache("https://example.com")
+	assert.NoError(t, err)
+	defer fc.Close()
+	b, err = io.ReadAll(fc)
+	assert.NoError(t, err)
+	assert.Equal(t, "<rss></rss>", string(b))
+}
+
+func Test_FeedArchive_Snapshot_Compressed(t *testing.T) {
+	s := newTestStorage(t)
+	err := s.Init("0.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir, err := s.JoinCacheDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := make([]*gofeed.Item, 100)
+	for i := range items {
+		items[i] = &gofeed.Item{Title: "Item", Link: "https://example.com/item", Content: "<p>Content of the item</p>"}
+	}
+	err = s.SaveFeedArchive("https://example.com", items)
+	assert.NoError(t, err)
+	err = s.SaveFeedSnapshot("https://example.com", &gofeed.Feed{Title: "Feed", Items: items})
+	assert.NoError(t, err)
+
+	archivePath, err := s.joinArchiveDir("https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{archivePath, path.Join(cacheDir, snapshotPrefix+url.QueryEscape("https://example.com"))} {
+		b, err := os.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, gzipMagic, b[:2])
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes, err := s.FeedCacheSizes()
+	assert.NoError(t, err)
+	assert.Less(t, sizes["https://example.com"], int64(len(raw)))
+
+	archived, err := s.LoadFeedArchive("https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, items, archived)
+	feed, err := s.LoadFeedSnapshot("https://example.com")
+	assert.NoError(t, err)
+	assert.Len(t, feed.Items, 100)
+	assert.Equal(t, "Feed", feed.Title)
+}
+
+func Test_FeedArchive_Uncompressed(t *testing.T) {
+	s := newTestStorage(t)
+	err := s.Init("0.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivePath, err := s.joinArchiveDir("https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFixture(t, archivePath, `[{"title":"Item"}]`)
+
+	archived, err := s.LoadFeedArchive("https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []*gofeed.Item{{Title: "Item"}}, archived)
+}
@@ -8,6 +8,10 @@ import (
 
 const (
 	configFile = "config.json"
+
+	// Default retention of the archived items
+	DefaultArchiveMaxAge   = "90d"
+	DefaultArchiveMaxItems = 1000
 )
 
 type Config struct {
@@ -16,6 +20,18 @@ type Config struct {
 	Styling  uint8           `json:"styling"` // 0: default, 1: enabled, 2: disabled
 	Summary  uint8           `json:"summary"` // 0: disabled, 1: enabled
 	ColorMap map[uint8]uint8 `json:"colorMap"`
+	Palette  []uint8         `json:"palette"` // colors assigned to feeds, empty for the default palette
+	Schema   map[string]int  `json:"schema"`  // schema version of each file, see migrate.go
+
+	ArchiveMaxAge   string `json:"archiveMaxAge"`   // e.g. 30d, empty for no limit
+	ArchiveMaxItems uint   `json:"archiveMaxItems"` // per feed, 0 for no limit
+
+	SyncRepo    string            `json:"syncRepo"`    // path to the git repository used by sync
+	SyncCommits map[string]string `json:"syncCommits"` // last synced commit of each sync repository
+
+	Template string `json:"template"` // default item template: a built-in name, a file or inline, empty for the default output
+
+	RemoteLists map[string]string `json:"remoteLists"` // list name to the URL of the OPML it is refreshed from
 }
 
 func (s *LocalStorage) LoadConfig() (*Config, error) {
@@ -38,6 +54,9 @@ func (s *LocalStorage) LoadConfig() (*Config, error) {
 	if s.config.ColorMap == nil {
 		s.config.ColorMap = make(map[uint8]uint8)
 	}
+	if s.config.Schema == nil {
+		s.config.Schema = make(map[string]int)
+	}
 	return s.config, nil
 }
 
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+const (
+	itemsFile = "items.json"
+)
+
+type ItemState struct {
+	Link      string    `json:"link,omitempty"`
+	FirstSeen time.Time `json:"firstSeen"`
+	Read      bool      `json:"read,omitempty"`
+}
+
+// LoadItemStates returns the item states keyed by feed URL and item ID.
+func (s *LocalStorage) LoadItemStates() (map[string]map[string]*ItemState, error) {
+	states := make(map[string]map[string]*ItemState)
+	path, err := s.JoinConfigDir(itemsFile)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(b, &states)
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (s *LocalStorage) SaveItemStates(states map[string]map[string]*ItemState) error {
+	path, err := s.JoinConfigDir(itemsFile)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// MarkItems sets the read state of the items of the given feeds. Items are
+// matched by ID or link. If items is empty, all items of the feeds are marked.
+// It returns which of the items were found.
+func (s *LocalStorage) MarkItems(feeds []string, items []string, read bool) ([]bool, error) {
+	states, err := s.LoadItemStates()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]bool, len(items))
+	for _, feed := range feeds {
+		for id, state := range states[feed] {
+			if len(items) == 0 {
+				state.Read = read
+				continue
+			}
+			for i := range items {
+				if items[i] == id || items[i] == state.Link {
+					state.Read = read
+					results[i] = true
+				}
+			}
+		}
+	}
+	return results, s.SaveItemStates(states)
+}
+
+func (s *LocalStorage) RemoveItemStates(feeds []string) error {
+	states, err := s.LoadItemStates()
+	if err != nil {
+		return err
+	}
+	for i := range feeds {
+		delete(states, feeds[i])
+	}
+	return s.SaveItemStates(states)
+}
+
+// MoveItemStates moves the item states of the feeds to their new addresses.
+// If both addresses have a state for an item, the earliest first seen time is
+// kept and the item is read if it was read in any of them.
+func (s *LocalStorage) MoveItemStates(replacements map[string]string) error {
+	states, err := s.LoadItemStates()
+	if err != nil {
+		return err
+	}
+	for from, to := range replacements {
+		moved, ok := states[from]
+		if !ok || from == to {
+			continue
+		}
+		delete(states, from)
+		if states[to] == nil {
+			states[to] = moved
+			continue
+		}
+		for id, state := range moved {
+			prev, ok := states[to][id]
+			if !ok {
+				states[to][id] = state
+				continue
+			}
+			if state.FirstSeen.Before(prev.FirstSeen) {
+				prev.FirstSeen = state.FirstSeen
+			}
+			prev.Read = prev.Read || state.Read
+		}
+	}
+	return s.SaveItemStates(states)
+}
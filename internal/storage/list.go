@@ -4,45 +4,84 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"net/url"
 	"os"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/radulucut/cleed/internal/utils"
+)
+
+// Metadata keys of a list item
+const (
+	ListItemTitle = "title" // overrides the title of the feed
+	ListItemColor = "color" // fixed color of the feed
+	ListItemNote  = "note"
+	ListItemTags  = "tags" // comma separated tags of the feed
+	ListItemSite  = "site" // URL of the website of the feed
 )
 
 type ListItem struct {
 	AddedAt time.Time
 	Address string
+	Meta    url.Values // nil if the item has no metadata
+}
+
+// Tags returns the tags of the feed.
+func (item *ListItem) Tags() []string {
+	return utils.ParseTags(item.Meta.Get(ListItemTags))
+}
+
+// AddToList adds the feeds that are not already in the list and returns the
+// number of feeds that were added.
+func (s *LocalStorage) AddToList(urls []string, list string) (int, error) {
+	items := make([]*ListItem, len(urls))
+	for i := range urls {
+		items[i] = &ListItem{Address: urls[i]}
+	}
+	return s.AddItemsToList(items, list)
 }
 
-func (s *LocalStorage) AddToList(urls []string, list string) error {
+// AddItemsToList adds the items that are not already in the list, comparing
+// them by utils.URLKey. The time the items were added at is set to the current
+// time. It returns the number of items that were added.
+func (s *LocalStorage) AddItemsToList(items []*ListItem, list string) (int, error) {
 	path, err := s.joinListsDir(list)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer f.Close()
 	m := make(map[string]*ListItem)
 	err = s.LoadFeedsFromList(m, list)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	keys := make(map[string]struct{}, len(m))
+	for address := range m {
+		keys[utils.URLKey(address)] = struct{}{}
 	}
 	now := s.time.Now()
-	for _, url := range urls {
-		_, ok := m[url]
-		if ok {
+	added := 0
+	for _, item := range items {
+		key := utils.URLKey(item.Address)
+		if _, ok := keys[key]; ok {
 			continue
 		}
-		_, err := f.Write(getListItemLine(now, url))
+		item.AddedAt = now
+		_, err := f.Write(getListItemLine(item))
 		if err != nil {
-			return err
+			return added, err
 		}
+		keys[key] = struct{}{}
+		added++
 	}
-	return nil
+	return added, nil
 }
 
 func (s *LocalStorage) RemoveFromList(urls []string, list string) ([]bool, error) {
@@ -53,20 +92,23 @@ func (s *LocalStorage) RemoveFromList(urls []string, list string) ([]bool, error
 	if len(l) == 0 {
 		return nil, fmt.Errorf("no items in list: %s", list)
 	}
+	// URLs are matched by their key, so e.g. "http://example.com/" removes
+	// "https://example.com"
+	keys := make([]string, len(urls))
+	for i := range urls {
+		keys[i] = utils.URLKey(urls[i])
+	}
 	results := make([]bool, len(urls))
 	remaining := make([]*ListItem, 0)
+	removed := make([]string, 0)
 	for i := range l {
-		remove := false
-		for j := range urls {
-			if l[i].Address == urls[j] {
-				remove = true
-				results[j] = true
-				break
-			}
-		}
-		if !remove {
+		j := slices.Index(keys, utils.URLKey(l[i].Address))
+		if j == -1 {
 			remaining = append(remaining, l[i])
+			continue
 		}
+		results[j] = true
+		removed = append(removed, l[i].Address)
 	}
 	path, err := s.joinListsDir(list)
 	if err != nil {
@@ -74,13 +116,13 @@ func (s *LocalStorage) RemoveFromList(urls []string, list string) ([]bool, error
 	}
 	b := new(bytes.Buffer)
 	for i := range remaining {
-		b.Write(getListItemLine(remaining[i].AddedAt, remaining[i].Address))
+		b.Write(getListItemLine(remaining[i]))
 	}
 	err = os.WriteFile(path, b.Bytes(), 0600)
 	if err != nil {
 		return nil, err
 	}
-	s.tidyCachesAfterRemove(urls, list)
+	s.tidyCachesAfterRemove(removed, list)
 	return results, nil
 }
 
@@ -147,24 +189,75 @@ func (s *LocalStorage) LoadLists() ([]string, error) {
 		if file.IsDir() {
 			continue
 		}
-		lists = append(lists, file.Name())
+		lists = append(lists, listName(file.Name()))
 	}
 	return lists, nil
 }
 
-func (s *LocalStorage) RenameList(oldName, newName string) error {
-	oldPath, err := s.joinListsDir(oldName)
+// LoadListTree returns the list followed by all its nested lists, e.g. eng,
+// eng/backend and eng/frontend for eng. The list itself is returned even if
+// it only groups other lists.
+func (s *LocalStorage) LoadListTree(list string) ([]string, error) {
+	lists, err := s.LoadLists()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	newPath, err := s.joinListsDir(newName)
+	tree := []string{list}
+	for i := range lists {
+		if IsNestedList(lists[i], list) {
+			tree = append(tree, lists[i])
+		}
+	}
+	slices.Sort(tree[1:])
+	return tree, nil
+}
+
+// IsNestedList reports whether list is nested under parent at any depth.
+func IsNestedList(list, parent string) bool {
+	return strings.HasPrefix(list, parent+"/")
+}
+
+// RenameList renames a list together with its nested lists.
+func (s *LocalStorage) RenameList(oldName, newName string) error {
+	if newName == oldName || IsNestedList(newName, oldName) {
+		return fmt.Errorf("cannot rename list %s to %s", oldName, newName)
+	}
+	tree, err := s.LoadListTree(oldName)
 	if err != nil {
 		return err
 	}
-	if _, err := os.Stat(newPath); err == nil {
-		return fmt.Errorf("list already exists: %s", newName)
+	paths := make(map[string][2]string, len(tree))
+	for i, list := range tree {
+		oldPath, err := s.joinListsDir(list)
+		if err != nil {
+			return err
+		}
+		newList := newName + strings.TrimPrefix(list, oldName)
+		newPath, err := s.joinListsDir(newList)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(newPath); err == nil {
+			return fmt.Errorf("list already exists: %s", newList)
+		}
+		// The list itself may only group other lists
+		if _, err := os.Stat(oldPath); err != nil && (i != 0 || len(tree) == 1) {
+			return err
+		}
+		paths[list] = [2]string{oldPath, newPath}
+	}
+	for _, list := range tree {
+		p := paths[list]
+		err = os.Rename(p[0], p[1])
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		err = s.renameRemoteList(list, newName+strings.TrimPrefix(list, oldName))
+		if err != nil {
+			return err
+		}
 	}
-	return os.Rename(oldPath, newPath)
+	return nil
 }
 
 func (s *LocalStorage) MergeLists(list, otherList string) error {
@@ -201,13 +294,17 @@ func (s *LocalStorage) MergeLists(list, otherList string) error {
 	})
 	b := new(bytes.Buffer)
 	for i := range listItems {
-		b.Write(getListItemLine(listItems[i].AddedAt, listItems[i].Address))
+		b.Write(getListItemLine(listItems[i]))
 	}
 	err = os.WriteFile(listPath, b.Bytes(), 0600)
 	if err != nil {
 		return err
 	}
-	return os.Remove(otherListPath)
+	err = os.Remove(otherListPath)
+	if err != nil {
+		return err
+	}
+	return s.renameRemoteList(otherList, "")
 }
 
 func (s *LocalStorage) RemoveList(list string) error {
@@ -223,6 +320,10 @@ func (s *LocalStorage) RemoveList(list string) error {
 	if err != nil {
 		return err
 	}
+	err = s.renameRemoteList(list, "")
+	if err != nil {
+		return err
+	}
 	if len(items) == 0 {
 		return nil
 	}
@@ -259,13 +360,125 @@ func (s *LocalStorage) tidyCachesAfterRemove(urls []string, list string) {
 		}
 	}
 	s.RemoveFeedCaches(feedsToRemove)
+	s.RemoveItemStates(feedsToRemove)
+	s.RemoveFeedArchives(feedsToRemove)
 }
 
-func getListItemLine(
-	addedAt time.Time,
-	address string,
-) []byte {
-	return []byte(fmt.Sprintf("%d %s\n", addedAt.Unix(), address))
+// ReplaceInLists replaces the addresses of feeds in all lists. If a list
+// already has the new address, the replaced feed is removed and its metadata
+// is added to the remaining one. The item states, starred and archived items
+// of the replaced feeds are moved to the new addresses and their caches are
+// removed.
+func (s *LocalStorage) ReplaceInLists(replacements map[string]string) error {
+	lists, err := s.LoadLists()
+	if err != nil {
+		return err
+	}
+	for _, list := range lists {
+		items, err := s.GetFeedsFromList(list)
+		if err != nil {
+			return err
+		}
+		kept := make(map[string]*ListItem, len(items))
+		remaining := make([]*ListItem, 0, len(items))
+		changed := false
+		for _, item := range items {
+			if address, ok := replacements[item.Address]; ok {
+				item.Address = address
+				changed = true
+			}
+			if prev, ok := kept[item.Address]; ok {
+				for k := range item.Meta {
+					if !prev.Meta.Has(k) {
+						if prev.Meta == nil {
+							prev.Meta = make(url.Values)
+						}
+						prev.Meta.Set(k, item.Meta.Get(k))
+					}
+				}
+				if item.AddedAt.Before(prev.AddedAt) {
+					prev.AddedAt = item.AddedAt
+				}
+				continue
+			}
+			kept[item.Address] = item
+			remaining = append(remaining, item)
+		}
+		if !changed {
+			continue
+		}
+		path, err := s.joinListsDir(list)
+		if err != nil {
+			return err
+		}
+		b := new(bytes.Buffer)
+		for i := range remaining {
+			b.Write(getListItemLine(remaining[i]))
+		}
+		err = os.WriteFile(path, b.Bytes(), 0600)
+		if err != nil {
+			return err
+		}
+	}
+	replaced := make([]string, 0, len(replacements))
+	for address := range replacements {
+		replaced = append(replaced, address)
+	}
+	s.RemoveFeedCaches(replaced)
+	err = s.MoveItemStates(replacements)
+	if err != nil {
+		return err
+	}
+	err = s.MoveStarred(replacements)
+	if err != nil {
+		return err
+	}
+	return s.MoveFeedArchives(replacements)
+}
+
+// SetListItemMeta updates the metadata of a feed in a list. Empty values
+// remove the key. It returns false if the feed is not in the list.
+func (s *LocalStorage) SetListItemMeta(list, address string, meta url.Values) (bool, error) {
+	items, err := s.GetFeedsFromList(list)
+	if err != nil {
+		return false, err
+	}
+	key := utils.URLKey(address)
+	idx := slices.IndexFunc(items, func(item *ListItem) bool {
+		return utils.URLKey(item.Address) == key
+	})
+	if idx == -1 {
+		return false, nil
+	}
+	item := items[idx]
+	if item.Meta == nil {
+		item.Meta = make(url.Values)
+	}
+	for k := range meta {
+		if meta.Get(k) == "" {
+			item.Meta.Del(k)
+		} else {
+			item.Meta.Set(k, meta.Get(k))
+		}
+	}
+	path, err := s.joinListsDir(list)
+	if err != nil {
+		return false, err
+	}
+	b := new(bytes.Buffer)
+	for i := range items {
+		b.Write(getListItemLine(items[i]))
+	}
+	return true, os.WriteFile(path, b.Bytes(), 0600)
+}
+
+// getListItemLine returns the line of a list item: the unix timestamp, the
+// address and, if any, the URL encoded metadata.
+func getListItemLine(item *ListItem) []byte {
+	if len(item.Meta) == 0 {
+		return []byte(fmt.Sprintf("%d %s\n", item.AddedAt.Unix(), item.Address))
+	}
+	return []byte(fmt.Sprintf("%d %s %s\n", item.AddedAt.Unix(), item.Address, item.Meta.Encode()))
 }
 
 func parseListItemLine(line string) (*ListItem, error) {
@@ -277,8 +490,15 @@ func parseListItemLine(line string) (*ListItem, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ListItem{
+	item := &ListItem{
 		AddedAt: time.Unix(addedAt, 0),
 		Address: parts[1],
-	}, nil
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		item.Meta, err = url.ParseQuery(parts[2])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
 }
@@ -0,0 +1,378 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	backupsDir = "backups"
+
+	schemaConfig    = "config"
+	schemaLists     = "lists"
+	schemaCacheInfo = "cacheInfo"
+	schemaItems     = "items"
+	schemaStarred   = "starred"
+	schemaArchive   = "archive"
+	schemaSnapshot  = "snapshot"
+)
+
+type migration struct {
+	file    string
+	version int
+	migrate func(s *LocalStorage) error // nil if there is nothing to convert, e.g. a new file
+}
+
+// Migrations are applied in order. Each one upgrades a single file (or group
+// of files) to the given version.
+var migrations = []*migration{
+	{file: schemaConfig, version: 1, migrate: migrateConfigV1},
+	{file: schemaLists, version: 1, migrate: migrateListsV1},
+	{file: schemaCacheInfo, version: 1, migrate: migrateCacheInfoV1},
+	{file: schemaItems, version: 1},
+	{file: schemaStarred, version: 1},
+	{file: schemaArchive, version: 1},
+	{file: schemaSnapshot, version: 1},
+	{file: schemaLists, version: 2}, // optional metadata field, older lines are still valid
+	{file: schemaLists, version: 3, migrate: migrateListsV3},
+	{file: schemaConfig, version: 2, migrate: migrateConfigV2},
+	{file: schemaArchive, version: 2, migrate: migrateArchiveV2},
+}
+
+// LatestSchema returns the latest schema version of every file.
+func LatestSchema() map[string]int {
+	schema := make(map[string]int)
+	for _, m := range migrations {
+		schema[m.file] = max(schema[m.file], m.version)
+	}
+	return schema
+}
+
+func (s *LocalStorage) Migrate() error {
+	config, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if config.Schema == nil {
+		config.Schema = make(map[string]int)
+	}
+	latest := LatestSchema()
+	for file, version := range config.Schema {
+		if version > latest[file] {
+			return fmt.Errorf("%s schema version %d is newer than the supported version %d", file, version, latest[file])
+		}
+	}
+	pending := make([]*migration, 0)
+	backup := false
+	for _, m := range migrations {
+		if m.version > config.Schema[m.file] {
+			pending = append(pending, m)
+			backup = backup || m.migrate != nil
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if backup {
+		err = s.backupBeforeMigrate()
+		if err != nil {
+			return fmt.Errorf("failed to backup before migration: %v", err)
+		}
+	}
+	for _, m := range pending {
+		if m.migrate != nil {
+			err = m.migrate(s)
+			if err != nil {
+				return fmt.Errorf("failed to migrate %s to version %d: %v", m.file, m.version, err)
+			}
+		}
+		config.Schema[m.file] = m.version
+		err = s.SaveConfig()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupBeforeMigrate copies the config, lists, item states, starred items and
+// cache info to backups/<unix-ts> in the config directory. Nothing is copied
+// when there are no lists and no cache info, e.g. for a fresh install.
+func (s *LocalStorage) backupBeforeMigrate() error {
+	files := make(map[string]string)
+	configPath, err := s.JoinConfigDir(configFile)
+	if err != nil {
+		return err
+	}
+	files[configFile] = configPath
+	itemsPath, err := s.JoinConfigDir(itemsFile)
+	if err != nil {
+		return err
+	}
+	files[itemsFile] = itemsPath
+	starredPath, err := s.JoinConfigDir(starredFile)
+	if err != nil {
+		return err
+	}
+	files[starredFile] = starredPath
+	cacheInfoPath, err := s.JoinCacheDir(cacheInfoFile)
+	if err != nil {
+		return err
+	}
+	files[cacheInfoFile] = cacheInfoPath
+	// The file names are used as they are, they may not be escaped yet
+	lists, err := s.listFiles()
+	if err != nil {
+		return err
+	}
+	for _, listPath := range lists {
+		files[path.Join(listsDir, path.Base(listPath))] = listPath
+	}
+	if len(lists) == 0 && !fileExists(cacheInfoPath) {
+		return nil
+	}
+	backupDir, err := s.JoinConfigDir(path.Join(backupsDir, strconv.FormatInt(s.time.Now().Unix(), 10)))
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(path.Join(backupDir, listsDir), 0755)
+	if err != nil {
+		return err
+	}
+	for name, src := range files {
+		err = copyFile(src, path.Join(backupDir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateConfigV1(s *LocalStorage) error {
+	config, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if config.Styling > 2 {
+		config.Styling = 0
+	}
+	if config.Summary > 1 {
+		config.Summary = 0
+	}
+	return s.SaveConfig()
+}
+
+// migrateConfigV2 sets the default archive retention, the archive was not
+// limited before.
+func migrateConfigV2(s *LocalStorage) error {
+	config, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if config.ArchiveMaxAge == "" {
+		config.ArchiveMaxAge = DefaultArchiveMaxAge
+	}
+	if config.ArchiveMaxItems == 0 {
+		config.ArchiveMaxItems = DefaultArchiveMaxItems
+	}
+	return s.SaveConfig()
+}
+
+// migrateArchiveV2 moves the archives from the cache directory to the config
+// directory.
+func migrateArchiveV2(s *LocalStorage) error {
+	cacheDir, err := s.JoinCacheDir("")
+	if err != nil {
+		return err
+	}
+	files, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	dir, err := s.joinArchiveDir("")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), archivePrefix) {
+			continue
+		}
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+		// Renaming fails if the directories are on different file systems
+		src := path.Join(cacheDir, file.Name())
+		dst := path.Join(dir, strings.TrimPrefix(file.Name(), archivePrefix))
+		err = copyFile(src, dst)
+		if err != nil {
+			return err
+		}
+		err = os.Remove(src)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateListsV1 drops blank and duplicate lines from the list files. Files
+// with lines that cannot be parsed are left untouched.
+func migrateListsV1(s *LocalStorage) error {
+	// Names of legacy files are only escaped by migrateListsV3
+	lists, err := s.listFiles()
+	if err != nil {
+		return err
+	}
+	for _, listPath := range lists {
+		b, err := os.ReadFile(listPath)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]struct{})
+		out := new(bytes.Buffer)
+		valid := true
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			item, err := parseListItemLine(line)
+			if err != nil {
+				valid = false
+				break
+			}
+			if _, ok := seen[item.Address]; ok {
+				continue
+			}
+			seen[item.Address] = struct{}{}
+			out.Write(getListItemLine(item))
+		}
+		if err = scanner.Err(); err != nil {
+			return err
+		}
+		if !valid || bytes.Equal(b, out.Bytes()) {
+			continue
+		}
+		err = os.WriteFile(listPath, out.Bytes(), 0600)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateListsV3 renames the list files with "%" in their name, which is now
+// used to escape the "/" of nested lists, so the name of the list is kept.
+func migrateListsV3(s *LocalStorage) error {
+	dir, err := s.joinListsDir("")
+	if err != nil {
+		return err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.Contains(file.Name(), "%") {
+			continue
+		}
+		err = os.Rename(path.Join(dir, file.Name()), path.Join(dir, listFileName(file.Name())))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCacheInfoV1 rewrites the cache info with all four fields. Lines that
+// cannot be parsed are dropped, the feeds will be fetched again.
+func migrateCacheInfoV1(s *LocalStorage) error {
+	cacheInfoPath, err := s.JoinCacheDir(cacheInfoFile)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(cacheInfoPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	cacheInfo := make(map[string]*CacheInfoItem)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.Count(line, " ") == 1 {
+			line += " "
+		}
+		item, err := parseCacheInfoItem(line)
+		if err != nil {
+			continue
+		}
+		cacheInfo[item.URL] = item
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	return s.SaveCacheInfo(cacheInfo)
+}
+
+// listFiles returns the paths of the list files without decoding their names.
+func (s *LocalStorage) listFiles() ([]string, error) {
+	dir, err := s.joinListsDir("")
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		paths = append(paths, path.Join(dir, file.Name()))
+	}
+	return paths, nil
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/radulucut/cleed/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	defaultCurrentTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func newTestStorage(t *testing.T) *LocalStorage {
+	ctrl := gomock.NewController(t)
+	timeMock := mocks.NewMockTime(ctrl)
+	timeMock.EXPECT().Now().Return(defaultCurrentTime).AnyTimes()
+	s := NewLocalStorage("cleed_test_storage", timeMock)
+	t.Cleanup(func() {
+		err := s.ClearAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	return s
+}
+
+func writeFixture(t *testing.T, name string, content string) {
+	err := os.MkdirAll(path.Dir(name), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(name, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Migrate_FromUnversioned(t *testing.T) {
+	s := newTestStorage(t)
+
+	configDir, err := s.JoinConfigDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir, err := s.JoinCacheDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := `{"version":"0.1.0","lastRun":"2023-12-31T00:00:00Z","styling":7,"summary":1,"colorMap":{"1":2}}`
+	list := fmt.Sprintf("%d %s\n\n%d %s\n%d %s\n",
+		defaultCurrentTime.Unix()-300, "https://example.com",
+		defaultCurrentTime.Unix(), "https://test.com",
+		defaultCurrentTime.Unix(), "https://example.com",
+	)
+	cacheInfo := fmt.Sprintf("%s %d %s\n%s %d %s %d\n",
+		"https://example.com", defaultCurrentTime.Unix(), "etag",
+		"https://test.com", defaultCurrentTime.Unix(), "", defaultCurrentTime.Unix()+300,
+	)
+	writeFixture(t, path.Join(configDir, configFile), config)
+	writeFixture(t, path.Join(configDir, listsDir, "default"), list)
+	writeFixture(t, path.Join(configDir, listsDir, "notes"), "not a list\n")
+	writeFixture(t, path.Join(cacheDir, cacheInfoFile), cacheInfo)
+
+	err = s.Init("0.2.0")
+	assert.NoError(t, err)
+
+	c, err := s.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.2.0", c.Version)
+	assert.Equal(t, LatestSchema(), c.Schema)
+	assert.Equal(t, uint8(0), c.Styling)
+	assert.Equal(t, uint8(1), c.Summary)
+	assert.Equal(t, map[uint8]uint8{1: 2}, c.ColorMap)
+
+	items, err := s.GetFeedsFromList("default")
+	assert.NoError(t, err)
+	assert.Equal(t, []*ListItem{
+		{AddedAt: time.Unix(defaultCurrentTime.Unix()-300, 0), Address: "https://example.com"},
+		{AddedAt: time.Unix(defaultCurrentTime.Unix(), 0), Address: "https://test.com"},
+	}, items)
+
+	b, err := os.ReadFile(path.Join(configDir, listsDir, "notes"))
+	assert.NoError(t, err)
+	assert.Equal(t, "not a list\n", string(b))
+
+	ci, err := s.LoadCacheInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*CacheInfoItem{
+		"https://example.com": {
+			URL:        "https://example.com",
+			LastFetch:  time.Unix(defaultCurrentTime.Unix(), 0),
+			ETag:       "etag",
+			FetchAfter: time.Unix(0, 0),
+		},
+		"https://test.com": {
+			URL:        "https://test.com",
+			LastFetch:  time.Unix(defaultCurrentTime.Unix(), 0),
+			ETag:       "",
+			FetchAfter: time.Unix(defaultCurrentTime.Unix()+300, 0),
+		},
+	}, ci)
+
+	backupDir := path.Join(configDir, backupsDir, fmt.Sprint(defaultCurrentTime.Unix()))
+	b, err = os.ReadFile(path.Join(backupDir, configFile))
+	assert.NoError(t, err)
+	assert.Equal(t, config, string(b))
+	b, err = os.ReadFile(path.Join(backupDir, listsDir, "default"))
+	assert.NoError(t, err)
+	assert.Equal(t, list, string(b))
+	b, err = os.ReadFile(path.Join(backupDir, cacheInfoFile))
+	assert.NoError(t, err)
+	assert.Equal(t, cacheInfo, string(b))
+}
+
+func Test_Migrate_FreshInstall(t *testing.T) {
+	s := newTestStorage(t)
+
+	err := s.Init("0.2.0")
+	assert.NoError(t, err)
+
+	c, err := s.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.2.0", c.Version)
+	assert.Equal(t, LatestSchema(), c.Schema)
+
+	configDir, err := s.JoinConfigDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoDirExists(t, path.Join(configDir, backupsDir))
+}
+
+func Test_Migrate_UpToDate(t *testing.T) {
+	s := newTestStorage(t)
+
+	configDir, err := s.JoinConfigDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(&Config{
+		Version: "0.2.0",
+		Schema:  LatestSchema(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFixture(t, path.Join(configDir, configFile), string(b))
+	writeFixture(t, path.Join(configDir, listsDir, "default"), fmt.Sprintf("%d %s\n\n", defaultCurrentTime.Unix(), "https://example.com"))
+
+	err = s.Init("0.2.0")
+	assert.NoError(t, err)
+
+	assert.NoDirExists(t, path.Join(configDir, backupsDir))
+	b, err = os.ReadFile(path.Join(configDir, listsDir, "default"))
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d %s\n\n", defaultCurrentTime.Unix(), "https://example.com"), string(b))
+}
+
+func Test_Migrate_NewerSchema(t *testing.T) {
+	s := newTestStorage(t)
+
+	configDir, err := s.JoinConfigDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := LatestSchema()
+	schema[schemaLists]++
+	b, err := json.Marshal(&Config{
+		Version: "9.0.0",
+		Schema:  schema,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFixture(t, path.Join(configDir, configFile), string(b))
+
+	err = s.Init("0.2.0")
+	assert.EqualError(t, err, fmt.Sprintf("lists schema version %d is newer than the supported version %d", schema[schemaLists], schema[schemaLists]-1))
+}
+
+func Test_Migrate_ListsV3(t *testing.T) {
+	s := newTestStorage(t)
+
+	configDir, err := s.JoinConfigDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := LatestSchema()
+	schema[schemaLists] = 2
+	b, err := json.Marshal(&Config{
+		Version: "0.2.0",
+		Schema:  schema,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := fmt.Sprintf("%d %s\n", defaultCurrentTime.Unix(), "https://example.com")
+	writeFixture(t, path.Join(configDir, configFile), string(b))
+	writeFixture(t, path.Join(configDir, listsDir, "100%"), list)
+	writeFixture(t, path.Join(configDir, listsDir, "default"), list)
+
+	err = s.Init("0.2.0")
+	assert.NoError(t, err)
+
+	lists, err := s.LoadLists()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"100%", "default"}, lists)
+	assert.FileExists(t, path.Join(configDir, listsDir, "100%25"))
+
+	items, err := s.GetFeedsFromList("100%")
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func Test_Migrate_FromUnversioned_Percent_List(t *testing.T) {
+	s := newTestStorage(t)
+
+	configDir, err := s.JoinConfigDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := fmt.Sprintf("%d %s\n\n%d %s\n",
+		defaultCurrentTime.Unix(), "https://example.com",
+		defaultCurrentTime.Unix(), "https://example.com",
+	)
+	writeFixture(t, path.Join(configDir, configFile), `{"version":"0.1.0"}`)
+	writeFixture(t, path.Join(configDir, listsDir, "50%off"), list)
+
+	err = s.Init("0.2.0")
+	assert.NoError(t, err)
+
+	lists, err := s.LoadLists()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"50%off"}, lists)
+	items, err := s.GetFeedsFromList("50%off")
+	assert.NoError(t, err)
+	assert.Equal(t, []*ListItem{
+		{AddedAt: time.Unix(defaultCurrentTime.Unix(), 0), Address: "https://example.com"},
+	}, items)
+
+	b, err := os.ReadFile(path.Join(configDir, backupsDir, fmt.Sprint(defaultCurrentTime.Unix()), listsDir, "50%off"))
+	assert.NoError(t, err)
+	assert.Equal(t, list, string(b))
+}
+
+func Test_Migrate_ArchiveV2(t *testing.T) {
+	s := newTestStorage(t)
+
+	configDir, err := s.JoinConfigDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir, err := s.JoinCacheDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := LatestSchema()
+	schema[schemaConfig] = 1
+	schema[schemaArchive] = 1
+	b, err := json.Marshal(&Config{
+		Version:         "0.2.0",
+		Schema:          schema,
+		ArchiveMaxItems: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFixture(t, path.Join(configDir, configFile), string(b))
+	writeFixture(t, path.Join(cacheDir, archivePrefix+url.QueryEscape("https://example.com")), `[{"title":"Item"}]`)
+
+	err = s.Init("0.2.0")
+	assert.NoError(t, err)
+
+	c, err := s.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultArchiveMaxAge, c.ArchiveMaxAge)
+	assert.Equal(t, uint(200), c.ArchiveMaxItems)
+
+	assert.NoFileExists(t, path.Join(cacheDir, archivePrefix+url.QueryEscape("https://example.com")))
+	archived, err := s.LoadFeedArchive("https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []*gofeed.Item{{Title: "Item"}}, archived)
+
+	err = s.ClearCache()
+	assert.NoError(t, err)
+	archived, err = s.LoadFeedArchive("https://example.com")
+	assert.NoError(t, err)
+	assert.Len(t, archived, 1)
+}
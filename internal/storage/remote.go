@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/radulucut/cleed/internal/utils"
+)
+
+// SetRemoteList binds a list to the URL of a remote OPML. An empty URL
+// removes the binding.
+func (s *LocalStorage) SetRemoteList(list, url string) error {
+	config, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if url == "" {
+		delete(config.RemoteLists, list)
+	} else {
+		if config.RemoteLists == nil {
+			config.RemoteLists = make(map[string]string)
+		}
+		config.RemoteLists[list] = url
+	}
+	return s.SaveConfig()
+}
+
+// UpdateListFromRemote sets the feeds of a list to the given items. It returns
+// the feeds that were added and removed. Feeds are compared by their URL key
+// and a remote without feeds is refused, so a broken OPML does not empty the
+// list. The title and tags of the remote items replace the ones of the feeds
+// that are kept, other metadata is left as is.
+func (s *LocalStorage) UpdateListFromRemote(list string, remoteItems []*ListItem) ([]string, []string, error) {
+	items, err := s.GetFeedsFromList(list)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(remoteItems) == 0 && len(items) > 0 {
+		return nil, nil, fmt.Errorf("remote OPML has no feeds, list %s was kept", list)
+	}
+	current := make(map[string]*ListItem, len(items))
+	for i := range items {
+		current[utils.URLKey(items[i].Address)] = items[i]
+	}
+	remote := make(map[string]struct{}, len(remoteItems))
+	added := make([]*ListItem, 0)
+	metaChanged := false
+	for _, item := range remoteItems {
+		if normalized, err := utils.NormalizeURL(item.Address); err == nil {
+			item.Address = normalized
+		}
+		key := utils.URLKey(item.Address)
+		if _, ok := remote[key]; ok {
+			continue
+		}
+		remote[key] = struct{}{}
+		existing, ok := current[key]
+		if !ok {
+			added = append(added, item)
+			continue
+		}
+		if setRemoteMeta(existing, item.Meta) {
+			metaChanged = true
+		}
+	}
+	removed := make([]string, 0)
+	for i := range items {
+		if _, ok := remote[utils.URLKey(items[i].Address)]; !ok {
+			removed = append(removed, items[i].Address)
+		}
+	}
+	if metaChanged {
+		path, err := s.joinListsDir(list)
+		if err != nil {
+			return nil, nil, err
+		}
+		b := new(bytes.Buffer)
+		for i := range items {
+			b.Write(getListItemLine(items[i]))
+		}
+		err = os.WriteFile(path, b.Bytes(), 0600)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	addedURLs := make([]string, len(added))
+	for i := range added {
+		addedURLs[i] = added[i].Address
+	}
+	if len(added) > 0 {
+		_, err = s.AddItemsToList(added, list)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(removed) > 0 {
+		_, err = s.RemoveFromList(removed, list)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return addedURLs, removed, nil
+}
+
+// setRemoteMeta sets the title and tags of a list item to the ones of a remote
+// item. Missing remote values keep the current ones. It returns true if the
+// metadata was changed.
+func setRemoteMeta(item *ListItem, meta url.Values) bool {
+	changed := false
+	for _, key := range []string{ListItemTitle, ListItemTags} {
+		value := meta.Get(key)
+		if value == "" || value == item.Meta.Get(key) {
+			continue
+		}
+		if item.Meta == nil {
+			item.Meta = make(url.Values)
+		}
+		item.Meta.Set(key, value)
+		changed = true
+	}
+	return changed
+}
+
+// renameRemoteList moves the binding of a list, if any, to its new name.
+func (s *LocalStorage) renameRemoteList(oldName, newName string) error {
+	config, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+	url, ok := config.RemoteLists[oldName]
+	if !ok {
+		return nil
+	}
+	delete(config.RemoteLists, oldName)
+	if newName != "" {
+		config.RemoteLists[newName] = url
+	}
+	return s.SaveConfig()
+}
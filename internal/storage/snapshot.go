@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/gob"
+	"net/url"
+	"os"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	snapshotPrefix = "parsed_"
+)
+
+// LoadFeedSnapshot returns the parsed feed saved with SaveFeedSnapshot.
+func (s *LocalStorage) LoadFeedSnapshot(name string) (*gofeed.Feed, error) {
+	path, err := s.JoinCacheDir(snapshotPrefix + url.QueryEscape(name))
+	if err != nil {
+		return nil, err
+	}
+	r, err := openCompressedFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	feed := &gofeed.Feed{}
+	err = gob.NewDecoder(r).Decode(feed)
+	if err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// SaveFeedSnapshot saves a gzip compressed binary snapshot of the parsed feed.
+// The snapshot is removed when the feed cache is saved again.
+func (s *LocalStorage) SaveFeedSnapshot(name string, feed *gofeed.Feed) error {
+	path, err := s.JoinCacheDir(snapshotPrefix + url.QueryEscape(name))
+	if err != nil {
+		return err
+	}
+	b := new(bytes.Buffer)
+	err = gob.NewEncoder(b).Encode(feed)
+	if err != nil {
+		return err
+	}
+	return writeFeedCacheFile(path, b)
+}
+
+func (s *LocalStorage) removeFeedSnapshot(name string) error {
+	path, err := s.JoinCacheDir(snapshotPrefix + url.QueryEscape(name))
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+const (
+	starredFile = "starred.json"
+)
+
+// StarredItem is a snapshot of an item, kept after it is no longer in the feed.
+type StarredItem struct {
+	ID        string     `json:"id"`
+	FeedURL   string     `json:"feedUrl"`
+	FeedTitle string     `json:"feedTitle"`
+	Title     string     `json:"title"`
+	Link      string     `json:"link"`
+	Published *time.Time `json:"published,omitempty"`
+	Updated   *time.Time `json:"updated,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	StarredAt time.Time  `json:"starredAt"`
+}
+
+func (s *LocalStorage) LoadStarred() ([]*StarredItem, error) {
+	path, err := s.JoinConfigDir(starredFile)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*StarredItem{}, nil
+		}
+		return nil, err
+	}
+	items := make([]*StarredItem, 0)
+	err = json.Unmarshal(b, &items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *LocalStorage) SaveStarred(items []*StarredItem) error {
+	path, err := s.JoinConfigDir(starredFile)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// AddStarred adds the items that are not already starred.
+func (s *LocalStorage) AddStarred(items []*StarredItem) error {
+	starred, err := s.LoadStarred()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		found := false
+		for i := range starred {
+			if starred[i].FeedURL == item.FeedURL && starred[i].ID == item.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			starred = append(starred, item)
+		}
+	}
+	return s.SaveStarred(starred)
+}
+
+// RemoveStarred removes the items matching the given IDs or links and returns
+// which of them were found.
+func (s *LocalStorage) RemoveStarred(items []string) ([]bool, error) {
+	starred, err := s.LoadStarred()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]bool, len(items))
+	remaining := make([]*StarredItem, 0, len(starred))
+	for _, item := range starred {
+		remove := false
+		for i := range items {
+			if items[i] == item.ID || items[i] == item.Link {
+				remove = true
+				results[i] = true
+			}
+		}
+		if !remove {
+			remaining = append(remaining, item)
+		}
+	}
+	return results, s.SaveStarred(remaining)
+}
+
+// RemoveStarredItem removes the item with the given ID of a feed. It returns
+// false if the item is not starred.
+func (s *LocalStorage) RemoveStarredItem(feedURL, id string) (bool, error) {
+	starred, err := s.LoadStarred()
+	if err != nil {
+		return false, err
+	}
+	remaining := make([]*StarredItem, 0, len(starred))
+	for _, item := range starred {
+		if item.FeedURL != feedURL || item.ID != id {
+			remaining = append(remaining, item)
+		}
+	}
+	if len(remaining) == len(starred) {
+		return false, nil
+	}
+	return true, s.SaveStarred(remaining)
+}
+
+// MoveStarred moves the starred items of the feeds to their new addresses.
+// Items that are already starred with the new address are removed.
+func (s *LocalStorage) MoveStarred(replacements map[string]string) error {
+	starred, err := s.LoadStarred()
+	if err != nil {
+		return err
+	}
+	type key struct{ feed, id string }
+	seen := make(map[key]struct{}, len(starred))
+	for _, item := range starred {
+		if _, ok := replacements[item.FeedURL]; !ok {
+			seen[key{item.FeedURL, item.ID}] = struct{}{}
+		}
+	}
+	remaining := make([]*StarredItem, 0, len(starred))
+	for _, item := range starred {
+		if to, ok := replacements[item.FeedURL]; ok {
+			k := key{to, item.ID}
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			item.FeedURL = to
+		}
+		remaining = append(remaining, item)
+	}
+	return s.SaveStarred(remaining)
+}
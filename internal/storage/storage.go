@@ -1,19 +1,25 @@
 package storage
 
 import (
+	"fmt"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/radulucut/cleed/internal/utils"
 )
 
 const (
-	listsDir = "lists"
+	listsDir    = "lists"
+	profilesDir = "profiles"
 )
 
 type LocalStorage struct {
-	name string
-	time utils.Time
+	name      string
+	time      utils.Time
+	profile   string
+	configDir string
+	cacheDir  string
 
 	config *Config
 }
@@ -28,6 +34,34 @@ func NewLocalStorage(
 	}
 }
 
+// SetProfile sets the profile to use. Each profile has its own config and
+// cache directories. An empty profile is the default one.
+func (s *LocalStorage) SetProfile(profile string) error {
+	if profile == "." || profile == ".." || strings.ContainsAny(profile, `/\`) {
+		return fmt.Errorf("invalid profile name: %s", profile)
+	}
+	s.profile = profile
+	s.config = nil
+	return nil
+}
+
+func (s *LocalStorage) GetProfile() string {
+	return s.profile
+}
+
+// SetConfigDir overrides the base config directory. If empty, the user config
+// directory is used.
+func (s *LocalStorage) SetConfigDir(dir string) {
+	s.configDir = dir
+	s.config = nil
+}
+
+// SetCacheDir overrides the base cache directory. If empty, the user cache
+// directory is used.
+func (s *LocalStorage) SetCacheDir(dir string) {
+	s.cacheDir = dir
+}
+
 func (s *LocalStorage) Init(version string) error {
 	configDir, err := s.JoinConfigDir("")
 	if err != nil {
@@ -49,31 +83,41 @@ func (s *LocalStorage) Init(version string) error {
 	if err != nil {
 		return err
 	}
-	_, err = s.LoadConfig()
+	config, err := s.LoadConfig()
 	if err != nil {
-		if os.IsNotExist(err) {
-			s.config = &Config{
-				Version:  version,
-				ColorMap: make(map[uint8]uint8),
-			}
-			err = s.SaveConfig()
+		if !os.IsNotExist(err) {
+			return err
+		}
+		config = &Config{
+			Version:  version,
+			ColorMap: make(map[uint8]uint8),
+			Schema:   make(map[string]int),
+		}
+		s.config = config
+		err = s.SaveConfig()
+		if err != nil {
+			return err
 		}
+	}
+	err = s.Migrate()
+	if err != nil {
 		return err
 	}
-	return s.Migrate()
-}
-
-func (s *LocalStorage) Migrate() error {
-	// handle migration here
+	if config.Version != version {
+		config.Version = version
+		return s.SaveConfig()
+	}
 	return nil
 }
 
+// ClearAll removes the config and cache of the current profile. The data of
+// the other profiles is kept.
 func (s *LocalStorage) ClearAll() error {
 	configDir, err := s.JoinConfigDir("")
 	if err != nil {
 		return err
 	}
-	err = os.RemoveAll(configDir)
+	err = removeProfileDir(configDir)
 	if err != nil {
 		return err
 	}
@@ -81,33 +125,92 @@ func (s *LocalStorage) ClearAll() error {
 	if err != nil {
 		return err
 	}
-	err = os.RemoveAll(cacheDir)
-	if err != nil {
-		return err
-	}
-	return nil
+	return removeProfileDir(cacheDir)
 }
 
 func (s *LocalStorage) JoinCacheDir(file string) (string, error) {
-	base, err := os.UserCacheDir()
-	if err != nil {
-		return "", err
+	base := s.cacheDir
+	if base == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		base = path.Join(dir, s.name)
 	}
-	return path.Join(base, s.name, file), nil
+	return path.Join(s.joinProfile(base), file), nil
 }
 
 func (s *LocalStorage) JoinConfigDir(file string) (string, error) {
-	base, err := os.UserConfigDir()
+	base := s.configDir
+	if base == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		base = path.Join(dir, s.name)
+	}
+	return path.Join(s.joinProfile(base), file), nil
+}
+
+func (s *LocalStorage) joinProfile(base string) string {
+	if s.profile == "" {
+		return base
+	}
+	return path.Join(base, profilesDir, s.profile)
+}
+
+// removeProfileDir removes the directory of a profile. The directory of the
+// default profile is only removed if there are no other profiles in it.
+func removeProfileDir(dir string) error {
+	err := removeDirEntries(dir, profilesDir)
 	if err != nil {
-		return "", err
+		return err
 	}
-	return path.Join(base, s.name, file), nil
+	// These fail if the directories are not empty
+	os.Remove(path.Join(dir, profilesDir))
+	os.Remove(dir)
+	return nil
 }
 
-func (s *LocalStorage) joinListsDir(file string) (string, error) {
+// joinListsDir returns the path of the file of a list, or of the lists
+// directory if list is empty.
+func (s *LocalStorage) joinListsDir(list string) (string, error) {
 	base, err := s.JoinConfigDir(listsDir)
 	if err != nil {
 		return "", err
 	}
-	return path.Join(base, file), nil
+	if list == "" {
+		return base, nil
+	}
+	if !ValidListName(list) {
+		return "", fmt.Errorf("invalid list name: %s", list)
+	}
+	return path.Join(base, listFileName(list)), nil
+}
+
+var (
+	listNameEscaper   = strings.NewReplacer("%", "%25", "/", "%2F")
+	listNameUnescaper = strings.NewReplacer("%25", "%", "%2F", "/")
+)
+
+// listFileName returns the name of the file of a list. Lists are nested with
+// "/" (e.g. eng/backend), which is escaped so all lists are in the same
+// directory.
+func listFileName(list string) string {
+	return listNameEscaper.Replace(list)
+}
+
+func listName(file string) string {
+	return listNameUnescaper.Replace(file)
+}
+
+// ValidListName reports whether the name of a list is valid. A list name is
+// made of one or more "/" separated parts that are not empty, "." or "..".
+func ValidListName(list string) bool {
+	for _, part := range strings.Split(list, "/") {
+		if part == "" || part == "." || part == ".." || strings.Contains(part, "\\") {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,519 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/radulucut/cleed/internal/utils"
+)
+
+const (
+	syncSettingsFile = "settings.json"
+	syncRemote       = "origin"
+)
+
+// SyncSettings are the settings shared through the sync repository.
+type SyncSettings struct {
+	Styling         uint8           `json:"styling"`
+	Summary         uint8           `json:"summary"`
+	ColorMap        map[uint8]uint8 `json:"colorMap"`
+	Palette         []uint8         `json:"palette"`
+	ArchiveMaxAge   string          `json:"archiveMaxAge"`
+	ArchiveMaxItems uint            `json:"archiveMaxItems"`
+}
+
+type SyncResult struct {
+	Committed bool
+	Merged    bool
+	Pushed    bool
+	Upstream  string
+	Conflicts []string
+	// Settings changed on both sides, the local values are kept
+	SettingsConflicts []string
+	Lists             int
+}
+
+// Sync uses a git repository as the source of truth for the lists and
+// settings. Local changes are committed, upstream changes are merged and
+// pushed back, then the merged lists and settings are applied locally.
+//
+// The last synced commit of each repository is recorded in the config. Without
+// it (e.g. the first sync or a new clone), the local lists are merged with the
+// ones in the repository and nothing is removed from it.
+func (s *LocalStorage) Sync(repo string) (*SyncResult, error) {
+	g, err := newGitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	result := &SyncResult{}
+	branch, err := g.run("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	_, err = g.run("remote", "get-url", syncRemote)
+	hasRemote := err == nil
+	if hasRemote {
+		_, err = g.run("fetch", syncRemote)
+		if err != nil {
+			return nil, err
+		}
+		if g.hasRef("refs/remotes/" + syncRemote + "/" + branch) {
+			result.Upstream = syncRemote + "/" + branch
+		}
+	}
+	base := config.SyncCommits[repo]
+	if base != "" && !g.hasRef(base+"^{commit}") {
+		base = ""
+	}
+	if base == "" && result.Upstream != "" {
+		// Bring the repository up to date first, so the local lists are
+		// merged with the latest upstream ones
+		if g.hasRef("HEAD") {
+			err = g.mergeInto(result)
+		} else {
+			_, err = g.run("reset", "--hard", result.Upstream)
+			result.Merged = err == nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.exportToRepo(g, base)
+	if err != nil {
+		return nil, err
+	}
+	result.Committed, err = g.commit("Update lists and settings")
+	if err != nil {
+		return nil, err
+	}
+	if result.Upstream != "" && base != "" {
+		err = g.mergeInto(result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if hasRemote && g.hasRef("HEAD") {
+		_, err = g.run("push", syncRemote, "HEAD:refs/heads/"+branch)
+		if err != nil {
+			return nil, err
+		}
+		result.Pushed = true
+	}
+	result.Lists, err = s.importFromRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if head, err := g.run("rev-parse", "HEAD"); err == nil {
+		if config.SyncCommits == nil {
+			config.SyncCommits = make(map[string]string)
+		}
+		config.SyncCommits[repo] = head
+		err = s.SaveConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// exportToRepo writes the local lists and settings to the working tree of the
+// repository. base is the last synced commit: lists that were in it and were
+// removed locally are removed from the repository. If base is empty, the local
+// lists are merged with the ones in the repository and existing settings are
+// kept.
+func (s *LocalStorage) exportToRepo(g *gitRepo, base string) error {
+	repoLists := path.Join(g.dir, listsDir)
+	err := os.MkdirAll(repoLists, 0755)
+	if err != nil {
+		return err
+	}
+	lists, err := s.LoadLists()
+	if err != nil {
+		return err
+	}
+	local := make(map[string]struct{}, len(lists))
+	for i := range lists {
+		name := listFileName(lists[i])
+		local[name] = struct{}{}
+		src, err := s.joinListsDir(lists[i])
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		dst := path.Join(repoLists, name)
+		if base == "" {
+			upstream, err := os.ReadFile(dst)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			b = mergeListFiles(nil, upstream, b)
+		}
+		err = os.WriteFile(dst, b, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	if base != "" {
+		synced, err := g.run("ls-tree", "--name-only", base, listsDir+"/")
+		if err != nil {
+			return err
+		}
+		for _, file := range strings.Split(synced, "\n") {
+			name := path.Base(file)
+			if file == "" {
+				continue
+			}
+			if _, ok := local[name]; ok {
+				continue
+			}
+			err = os.Remove(path.Join(repoLists, name))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	settingsPath := path.Join(g.dir, syncSettingsFile)
+	if base == "" && fileExists(settingsPath) {
+		return nil
+	}
+	config, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(&SyncSettings{
+		Styling:         config.Styling,
+		Summary:         config.Summary,
+		ColorMap:        config.ColorMap,
+		Palette:         config.Palette,
+		ArchiveMaxAge:   config.ArchiveMaxAge,
+		ArchiveMaxItems: config.ArchiveMaxItems,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(settingsPath, append(b, '\n'), 0644)
+}
+
+// importFromRepo replaces the local lists and settings with the ones from the
+// repository. It returns the number of lists.
+func (s *LocalStorage) importFromRepo(repo string) (int, error) {
+	dir, err := s.JoinConfigDir(listsDir)
+	if err != nil {
+		return 0, err
+	}
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return 0, err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return 0, err
+	}
+	repoLists := path.Join(repo, listsDir)
+	files, err := os.ReadDir(repoLists)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	count := 0
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		err = copyFile(path.Join(repoLists, file.Name()), path.Join(dir, file.Name()))
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	b, err := os.ReadFile(path.Join(repo, syncSettingsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return count, nil
+		}
+		return 0, err
+	}
+	settings := &SyncSettings{}
+	err = json.Unmarshal(b, settings)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %v", syncSettingsFile, err)
+	}
+	config, err := s.LoadConfig()
+	if err != nil {
+		return 0, err
+	}
+	config.Styling = settings.Styling
+	config.Summary = settings.Summary
+	config.ColorMap = settings.ColorMap
+	if config.ColorMap == nil {
+		config.ColorMap = make(map[uint8]uint8)
+	}
+	config.Palette = settings.Palette
+	config.ArchiveMaxAge = settings.ArchiveMaxAge
+	config.ArchiveMaxItems = settings.ArchiveMaxItems
+	return count, s.SaveConfig()
+}
+
+type gitRepo struct {
+	dir  string
+	args []string // arguments added to every command, e.g. a fallback identity
+}
+
+func newGitRepo(dir string) (*gitRepo, error) {
+	g := &gitRepo{dir: dir}
+	_, err := g.run("rev-parse", "--git-dir")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git repository: %v", dir, err)
+	}
+	if email, _ := g.run("config", "user.email"); email == "" {
+		g.args = []string{"-c", "user.name=cleed", "-c", "user.email=cleed@localhost"}
+	}
+	return g, nil
+}
+
+func (g *gitRepo) hasRef(ref string) bool {
+	_, err := g.run("rev-parse", "--verify", "--quiet", ref)
+	return err == nil
+}
+
+func (g *gitRepo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", append(append([]string{"-C", g.dir}, g.args...), args...)...)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// commit commits the changes of the lists and settings. It returns false if
+// there was nothing to commit.
+func (g *gitRepo) commit(message string) (bool, error) {
+	_, err := g.run("add", "-A", "--", listsDir, syncSettingsFile)
+	if err != nil {
+		return false, err
+	}
+	status, err := g.run("status", "--porcelain", "--", listsDir, syncSettingsFile)
+	if err != nil {
+		return false, err
+	}
+	if status == "" {
+		return false, nil
+	}
+	_, err = g.run("commit", "-m", message)
+	return err == nil, err
+}
+
+// mergeInto merges the upstream branch of the result and records what was
+// merged and the conflicts.
+func (g *gitRepo) mergeInto(result *SyncResult) error {
+	merged, conflicts, settings, err := g.merge(result.Upstream)
+	if err != nil {
+		return err
+	}
+	result.Merged = result.Merged || merged
+	result.Conflicts = append(result.Conflicts, conflicts...)
+	result.SettingsConflicts = append(result.SettingsConflicts, settings...)
+	return nil
+}
+
+// merge merges the upstream branch. Conflicts are resolved against the merge
+// base: removals from either side are applied to lists and then the new feeds
+// of both sides are added, settings changed on one side take that value and
+// settings changed on both sides keep the local value. Other files keep the
+// local version. It returns whether anything was merged, the conflicting files
+// and the conflicting settings.
+func (g *gitRepo) merge(upstream string) (bool, []string, []string, error) {
+	if _, err := g.run("merge-base", "--is-ancestor", upstream, "HEAD"); err == nil {
+		return false, nil, nil, nil
+	}
+	_, err := g.run("merge", "--no-edit", "--allow-unrelated-histories", upstream)
+	if err == nil {
+		return true, nil, nil, nil
+	}
+	out, lerr := g.run("diff", "--name-only", "--diff-filter=U")
+	if lerr != nil || out == "" {
+		g.run("merge", "--abort")
+		return false, nil, nil, err
+	}
+	conflicts := strings.Split(out, "\n")
+	settingsConflicts := make([]string, 0)
+	for _, file := range conflicts {
+		// The base is missing if the file was added on both sides
+		base, _ := g.run("show", ":1:"+file)
+		ours, oerr := g.run("show", ":2:"+file)
+		theirs, terr := g.run("show", ":3:"+file)
+		var content []byte
+		switch {
+		case oerr == nil && terr == nil && path.Dir(file) == listsDir:
+			content = mergeListFiles([]byte(base), []byte(ours), []byte(theirs))
+		case oerr == nil && terr == nil && file == syncSettingsFile:
+			var fields []string
+			content, fields, err = mergeSettings([]byte(base), []byte(ours), []byte(theirs))
+			if err != nil {
+				g.run("merge", "--abort")
+				return false, nil, nil, err
+			}
+			settingsConflicts = append(settingsConflicts, fields...)
+		case oerr == nil:
+			content = []byte(ours + "\n")
+		default:
+			content = []byte(theirs + "\n")
+		}
+		err = os.WriteFile(path.Join(g.dir, file), content, 0644)
+		if err != nil {
+			g.run("merge", "--abort")
+			return false, nil, nil, err
+		}
+	}
+	_, err = g.run("add", "-A")
+	if err != nil {
+		g.run("merge", "--abort")
+		return false, nil, nil, err
+	}
+	_, err = g.run("commit", "--no-edit")
+	if err != nil {
+		g.run("merge", "--abort")
+		return false, nil, nil, err
+	}
+	return true, conflicts, settingsConflicts, nil
+}
+
+// mergeListFiles merges two versions of a list with their common base. Feeds
+// of the base that were removed on either side are removed, then the feeds of
+// ours are followed by the new feeds of theirs. Feeds are compared by their
+// URL key and ours wins for feeds that changed on both sides. Without a base,
+// the feeds of both sides are kept.
+func mergeListFiles(base, ours, theirs []byte) []byte {
+	baseKeys := listFileKeys(base)
+	oursKeys := listFileKeys(ours)
+	theirsKeys := listFileKeys(theirs)
+	out := new(bytes.Buffer)
+	seen := make(map[string]struct{})
+	for _, side := range []struct {
+		b     []byte
+		other map[string]struct{}
+	}{{ours, theirsKeys}, {theirs, oursKeys}} {
+		scanner := bufio.NewScanner(bytes.NewReader(side.b))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			key := listLineKey(line)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			// Removed on the other side
+			_, inBase := baseKeys[key]
+			_, inOther := side.other[key]
+			if inBase && !inOther {
+				continue
+			}
+			seen[key] = struct{}{}
+			out.WriteString(line + "\n")
+		}
+	}
+	return out.Bytes()
+}
+
+func listFileKeys(b []byte) map[string]struct{} {
+	keys := make(map[string]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			keys[listLineKey(line)] = struct{}{}
+		}
+	}
+	return keys
+}
+
+func listLineKey(line string) string {
+	if item, err := parseListItemLine(line); err == nil {
+		return utils.URLKey(item.Address)
+	}
+	return line
+}
+
+// mergeSettings merges two versions of the settings with their common base.
+// A setting changed on one side takes the value of that side, a setting
+// changed on both sides keeps the value of ours. It returns the merged
+// settings and the names of the settings changed on both sides.
+func mergeSettings(base, ours, theirs []byte) ([]byte, []string, error) {
+	values := make([]map[string]json.RawMessage, 3)
+	for i, b := range [][]byte{base, ours, theirs} {
+		values[i] = make(map[string]json.RawMessage)
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+		err := json.Unmarshal(b, &values[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %v", syncSettingsFile, err)
+		}
+	}
+	baseValues, merged, theirsValues := values[0], values[1], values[2]
+	conflicts := make([]string, 0)
+	for key, value := range theirsValues {
+		ourValue, ok := merged[key]
+		if ok && jsonEqual(ourValue, value) {
+			continue
+		}
+		baseValue, inBase := baseValues[key]
+		switch {
+		case inBase && ok && jsonEqual(baseValue, ourValue):
+			merged[key] = value
+		case inBase && jsonEqual(baseValue, value):
+		case !ok && !inBase:
+			merged[key] = value
+		default:
+			conflicts = append(conflicts, key)
+		}
+	}
+	slices.Sort(conflicts)
+	// Written in the same format as exportToRepo, so it is not changed again
+	// by the next sync
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings := &SyncSettings{}
+	err = json.Unmarshal(b, settings)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %v", syncSettingsFile, err)
+	}
+	b, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(b, '\n'), conflicts, nil
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// BuiltinTemplates are the templates that can be used by name.
+var BuiltinTemplates = map[string]string{
+	"compact":  `{{color .FeedColor (pad 20 (truncate 20 .Feed.Title))}}  {{if .IsNew}}{{color (mapcolor 10) "• "}}{{end}}{{.Item.Title}}  {{color (mapcolor 7) .Item.Link}}`,
+	"markdown": `- [{{.Item.Title}}]({{.Item.Link}}) ({{.Feed.Title}}, {{date "2006-01-02" .Item.PublishedParsed}})`,
+	"org":      `* [[{{.Item.Link}}][{{.Item.Title}}]] :{{.Feed.Title}}:`,
+}
+
+// ParseTemplate parses a built-in template by name, a template file or an
+// inline template. The template is executed for each item with the FeedItem
+// as data.
+func (f *TerminalFeed) ParseTemplate(s string) (*template.Template, error) {
+	text, ok := BuiltinTemplates[s]
+	if !ok {
+		text = s
+		if fi, err := os.Stat(s); err == nil && !fi.IsDir() {
+			b, err := os.ReadFile(s)
+			if err != nil {
+				return nil, err
+			}
+			text = strings.TrimSuffix(string(b), "\n")
+		}
+	}
+	return template.New("item").Funcs(f.templateFuncs()).Parse(text)
+}
+
+func (f *TerminalFeed) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// color is only applied if styling is enabled. FeedColor is already
+		// mapped using the color map
+		"color": func(color uint8, s string) string {
+			return f.printer.ColorForeground(s, color)
+		},
+		// mapcolor maps a fixed color using the color map, like the colors of
+		// the default output
+		"mapcolor": func(color uint8) uint8 {
+			config, err := f.storage.LoadConfig()
+			if err != nil {
+				return color
+			}
+			return mapColor(color, config)
+		},
+		"pad": func(width int, s string) string {
+			return runewidth.FillRight(s, width)
+		},
+		"truncate": func(width int, s string) string {
+			return runewidth.Truncate(s, width, "...")
+		},
+		"date": func(layout string, t *time.Time) string {
+			if t == nil || t.IsZero() {
+				return ""
+			}
+			return t.Format(layout)
+		},
+		"join": func(sep string, a []string) string {
+			return strings.Join(a, sep)
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// BuiltinTemplateNames returns the sorted names of the built-in templates.
+func BuiltinTemplateNames() []string {
+	names := make([]string, 0, len(BuiltinTemplates))
+	for name := range BuiltinTemplates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// outputTemplate writes each item using the template, one item per line.
+func (f *TerminalFeed) outputTemplate(items []*FeedItem, tmpl *template.Template) error {
+	b := new(bytes.Buffer)
+	for i := len(items) - 1; i >= 0; i-- {
+		b.Reset()
+		err := tmpl.Execute(b, items[i])
+		if err != nil {
+			return err
+		}
+		f.printer.Print(b.String(), "\n")
+	}
+	return nil
+}
@@ -0,0 +1,512 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/radulucut/cleed/internal/storage"
+	"github.com/radulucut/cleed/internal/utils"
+	"golang.org/x/term"
+)
+
+// Keys read from the input
+const (
+	keyCtrlC     rune = 3
+	keyBackspace rune = 8
+	keyEnter     rune = '\r'
+	keyEscape    rune = 27
+	keyDelete    rune = 127
+)
+
+// Arrow keys, which are read from escape sequences
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyRight
+	keyLeft
+)
+
+const (
+	tuiFeedWidth      = 20
+	tuiPublishedWidth = 14
+	// Size used if the output is not a terminal
+	tuiDefaultWidth  = 80
+	tuiDefaultHeight = 24
+)
+
+type tui struct {
+	f           *TerminalFeed
+	opts        *FeedOptions
+	config      *storage.Config
+	in          *bufio.Reader
+	interactive bool
+
+	items   []*FeedItem
+	starred map[string]struct{} // starred items, by tuiItemKey
+	cursor  int
+	offset  int
+	query   string
+
+	view       *FeedItem // item whose content is displayed, nil for the list
+	viewOffset int
+
+	prompt *tuiPrompt
+	status string
+}
+
+// tuiPrompt reads a line, e.g. the list to filter by.
+type tuiPrompt struct {
+	label string
+	text  []rune
+	done  func(text string)
+}
+
+// TUI displays the items in a scrollable list, filtered by the query if it is
+// not empty. Keys are read from the input of the printer, which is set to raw
+// mode if it is a terminal.
+func (f *TerminalFeed) TUI(query string, opts *FeedOptions) error {
+	if f.printer.InReader == nil {
+		return utils.NewInternalError("no input to read keys from")
+	}
+	query = strings.TrimSpace(query)
+	if query != "" {
+		opts.Query = utils.Tokenize(query, nil)
+		if len(opts.Query) == 0 {
+			return utils.NewInternalError("query is empty")
+		}
+	}
+	config, err := f.storage.LoadConfig()
+	if err != nil {
+		return utils.NewInternalError("failed to load config: " + err.Error())
+	}
+	t := &tui{
+		f:      f,
+		opts:   opts,
+		config: config,
+		in:     bufio.NewReader(f.printer.InReader),
+		query:  query,
+	}
+	err = t.load()
+	if err != nil {
+		return err
+	}
+	if file, ok := f.printer.InReader.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		state, err := term.MakeRaw(int(file.Fd()))
+		if err != nil {
+			return utils.NewInternalError("failed to set up terminal: " + err.Error())
+		}
+		defer term.Restore(int(file.Fd()), state)
+		t.interactive = true
+		// Use the alternate screen and hide the cursor
+		f.printer.Print("\033[?1049h\033[?25l")
+		defer f.printer.Print("\033[?25h\033[?1049l")
+	}
+	for {
+		t.render()
+		key, err := t.readKey()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return utils.NewInternalError("failed to read input: " + err.Error())
+		}
+		if !t.handleKey(key) {
+			return nil
+		}
+	}
+}
+
+// load processes the feeds and keeps the selected item if it is still listed.
+// Messages printed while processing the feeds, e.g. fetch errors, are shown in
+// the status line so they do not break the screen.
+func (t *tui) load() error {
+	selected := ""
+	if len(t.items) > 0 {
+		selected = tuiItemKey(t.items[t.cursor])
+	}
+	messages := &tuiMessages{}
+	items, err := t.processFeeds(messages)
+	if err != nil {
+		return err
+	}
+	if n := len(messages.lines); n > 0 {
+		t.status = messages.lines[n-1]
+		if n > 1 {
+			t.status += fmt.Sprintf(" (and %d more)", n-1)
+		}
+	}
+	if len(t.opts.Query) > 0 {
+		sortByScore(items)
+	} else {
+		sortByPublished(items)
+	}
+	starred, err := t.f.storage.LoadStarred()
+	if err != nil {
+		return utils.NewInternalError("failed to load starred items: " + err.Error())
+	}
+	t.starred = make(map[string]struct{}, len(starred))
+	for _, s := range starred {
+		t.starred[s.FeedURL+" "+s.ID] = struct{}{}
+	}
+	now := t.f.time.Now().Unix()
+	t.cursor = 0
+	for i, fi := range items {
+		fi.PublishedRelative = utils.Relative(now - fi.Item.PublishedParsed.Unix())
+		if tuiItemKey(fi) == selected {
+			t.cursor = i
+		}
+	}
+	t.items = items
+	return nil
+}
+
+// processFeeds fetches the items, writing the messages of the printer to
+// messages instead of the terminal.
+func (t *tui) processFeeds(messages *tuiMessages) ([]*FeedItem, error) {
+	out, errOut := t.f.printer.OutWriter, t.f.printer.ErrWriter
+	t.f.printer.OutWriter, t.f.printer.ErrWriter = messages, messages
+	defer func() {
+		t.f.printer.OutWriter, t.f.printer.ErrWriter = out, errOut
+	}()
+	return t.f.processFeeds(t.opts, t.config, &RunSummary{Start: t.f.time.Now()})
+}
+
+// tuiMessages collects the lines written to it. Feeds are processed
+// concurrently, so writes are synchronized.
+type tuiMessages struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (m *tuiMessages) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			m.lines = append(m.lines, line)
+		}
+	}
+	return len(p), nil
+}
+
+func tuiItemKey(fi *FeedItem) string {
+	return fi.FeedURL + " " + itemID(fi.Item)
+}
+
+func (t *tui) readKey() (rune, error) {
+	r, _, err := t.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r != keyEscape || t.in.Buffered() == 0 {
+		return r, nil
+	}
+	next, _, err := t.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if next != '[' && next != 'O' {
+		t.in.UnreadRune()
+		return r, nil
+	}
+	code, _, err := t.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	switch code {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	}
+	return 0, nil
+}
+
+// handleKey returns false if the TUI should exit.
+func (t *tui) handleKey(key rune) bool {
+	t.status = ""
+	if key == keyCtrlC {
+		return false
+	}
+	if t.prompt != nil {
+		t.handlePromptKey(key)
+		return true
+	}
+	if t.view != nil {
+		switch key {
+		case 'q', 'h', keyEscape, keyLeft:
+			t.view = nil
+		case 'j', keyDown:
+			t.viewOffset++
+		case 'k', keyUp:
+			t.viewOffset = max(t.viewOffset-1, 0)
+		case 'r':
+			t.toggleRead(t.view)
+		case 's':
+			t.toggleStar(t.view)
+		}
+		return true
+	}
+	switch key {
+	case 'q':
+		return false
+	case 'j', keyDown:
+		t.cursor = min(t.cursor+1, max(len(t.items)-1, 0))
+	case 'k', keyUp:
+		t.cursor = max(t.cursor-1, 0)
+	case 'g':
+		t.cursor = 0
+	case 'G':
+		t.cursor = max(len(t.items)-1, 0)
+	case keyEnter, '\n', 'o', keyRight:
+		if len(t.items) == 0 {
+			break
+		}
+		t.view = t.items[t.cursor]
+		t.viewOffset = 0
+		if !t.view.IsRead {
+			t.toggleRead(t.view)
+			t.status = ""
+		}
+	case 'r':
+		if len(t.items) > 0 {
+			t.toggleRead(t.items[t.cursor])
+		}
+	case 's':
+		if len(t.items) > 0 {
+			t.toggleStar(t.items[t.cursor])
+		}
+	case 'l':
+		t.prompt = &tuiPrompt{label: "list", text: []rune(t.opts.List), done: t.filterList}
+	case '/':
+		t.prompt = &tuiPrompt{label: "search", text: []rune(t.query), done: t.search}
+	case 'R':
+		t.refresh()
+	}
+	return true
+}
+
+func (t *tui) handlePromptKey(key rune) {
+	p := t.prompt
+	switch key {
+	case keyEnter, '\n':
+		t.prompt = nil
+		p.done(string(p.text))
+	case keyEscape:
+		t.prompt = nil
+	case keyBackspace, keyDelete:
+		if len(p.text) > 0 {
+			p.text = p.text[:len(p.text)-1]
+		}
+	default:
+		if key > 0 && unicode.IsPrint(key) {
+			p.text = append(p.text, key)
+		}
+	}
+}
+
+func (t *tui) refresh() {
+	err := t.load()
+	if err != nil {
+		t.status = err.Error()
+		return
+	}
+	if t.status == "" {
+		t.status = "refreshed " + utils.Pluralize(int64(len(t.items)), "item")
+	}
+}
+
+func (t *tui) filterList(list string) {
+	prev := t.opts.List
+	t.opts.List = strings.TrimSpace(list)
+	err := t.load()
+	if err != nil {
+		t.opts.List = prev
+		t.status = err.Error()
+	}
+}
+
+func (t *tui) search(query string) {
+	prev, prevQuery := t.opts.Query, t.query
+	t.query = strings.TrimSpace(query)
+	t.opts.Query = utils.Tokenize(t.query, nil)
+	err := t.load()
+	if err != nil {
+		t.opts.Query, t.query = prev, prevQuery
+		t.status = err.Error()
+	}
+}
+
+func (t *tui) toggleRead(fi *FeedItem) {
+	_, err := t.f.storage.MarkItems([]string{fi.FeedURL}, []string{itemID(fi.Item)}, !fi.IsRead)
+	if err != nil {
+		t.status = "failed to update item: " + err.Error()
+		return
+	}
+	fi.IsRead = !fi.IsRead
+	if fi.IsRead {
+		t.status = "marked as read"
+	} else {
+		t.status = "marked as unread"
+	}
+}
+
+func (t *tui) toggleStar(fi *FeedItem) {
+	key := tuiItemKey(fi)
+	if _, ok := t.starred[key]; ok {
+		_, err := t.f.storage.RemoveStarredItem(fi.FeedURL, itemID(fi.Item))
+		if err != nil {
+			t.status = "failed to unstar item: " + err.Error()
+			return
+		}
+		delete(t.starred, key)
+		t.status = "unstarred"
+		return
+	}
+	err := t.f.storage.AddStarred([]*storage.StarredItem{t.f.newStarredItem(fi.FeedURL, fi.Feed, fi.Item)})
+	if err != nil {
+		t.status = "failed to star item: " + err.Error()
+		return
+	}
+	t.starred[key] = struct{}{}
+	t.status = "starred"
+}
+
+func (t *tui) size() (int, int) {
+	width, height := t.f.printer.GetSize()
+	if width == math.MaxInt {
+		width = tuiDefaultWidth
+	}
+	if height == math.MaxInt {
+		height = tuiDefaultHeight
+	}
+	return width, max(height, 3)
+}
+
+func (t *tui) render() {
+	width, height := t.size()
+	var lines []string
+	if t.view != nil {
+		lines = t.renderItem(width, height-1)
+	} else {
+		lines = t.renderList(width, height-1)
+	}
+	lines = append(lines, t.renderFooter(width))
+	if t.interactive {
+		t.f.printer.Print("\033[H\033[2J", strings.Join(lines, "\r\n"))
+		return
+	}
+	t.f.printer.Print(strings.Join(lines, "\n"), "\n")
+}
+
+func (t *tui) renderList(width, height int) []string {
+	list := t.opts.List
+	if list == "" {
+		list = "all"
+	}
+	header := "cleed | list: " + list
+	if t.query != "" {
+		header += " | search: " + t.query
+	}
+	if len(t.items) > 0 {
+		header += fmt.Sprintf(" | %d/%d", t.cursor+1, len(t.items))
+	}
+	lines := []string{runewidth.Truncate(header, width, "...")}
+	if len(t.items) == 0 {
+		return append(lines, "no items to display")
+	}
+	body := height - 1
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+body {
+		t.offset = t.cursor - body + 1
+	}
+	secondaryTextColor := mapColor(7, t.config)
+	for i := t.offset; i < min(len(t.items), t.offset+body); i++ {
+		fi := t.items[i]
+		prefix := "  "
+		if i == t.cursor {
+			prefix = "> "
+		}
+		if _, ok := t.starred[tuiItemKey(fi)]; ok {
+			prefix += "*"
+		} else {
+			prefix += " "
+		}
+		if fi.IsRead {
+			prefix += "  "
+		} else {
+			prefix += "• "
+		}
+		feedTitle := runewidth.FillRight(runewidth.Truncate(fi.Feed.Title, tuiFeedWidth, "..."), tuiFeedWidth)
+		published := runewidth.FillRight(fi.PublishedRelative, tuiPublishedWidth)
+		title := runewidth.Truncate(fi.Item.Title, max(width-runewidth.StringWidth(prefix)-tuiFeedWidth-tuiPublishedWidth-4, 0), "...")
+		if fi.IsRead {
+			title = t.f.printer.ColorForeground(title, secondaryTextColor)
+		}
+		lines = append(lines, strings.TrimRight(
+			prefix+
+				t.f.printer.ColorForeground(feedTitle, fi.FeedColor)+"  "+
+				t.f.printer.ColorForeground(published, secondaryTextColor)+"  "+
+				title,
+			" ",
+		))
+	}
+	return lines
+}
+
+func (t *tui) renderItem(width, height int) []string {
+	fi := t.view
+	lines := strings.Split(runewidth.Wrap(fi.Item.Title, width), "\n")
+	meta := fi.Feed.Title + " | " + fi.PublishedRelative
+	if fi.Item.PublishedParsed != nil && !fi.Item.PublishedParsed.IsZero() {
+		meta += " (" + fi.Item.PublishedParsed.Format("2006-01-02 15:04") + ")"
+	}
+	secondaryTextColor := mapColor(7, t.config)
+	lines = append(lines,
+		t.f.printer.ColorForeground(runewidth.Truncate(meta, width, "..."), fi.FeedColor),
+		t.f.printer.ColorForeground(fi.Item.Link, secondaryTextColor),
+		"",
+	)
+	content := fi.Item.Content
+	if content == "" {
+		content = fi.Item.Description
+	}
+	text := utils.HTMLToText(content)
+	if text == "" {
+		text = "no content"
+	}
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, strings.Split(runewidth.Wrap(line, width), "\n")...)
+	}
+	t.viewOffset = min(t.viewOffset, max(len(lines)-height, 0))
+	return lines[t.viewOffset:min(len(lines), t.viewOffset+height)]
+}
+
+func (t *tui) renderFooter(width int) string {
+	var footer string
+	switch {
+	case t.prompt != nil:
+		footer = t.prompt.label + ": " + string(t.prompt.text) + "_"
+	case t.status != "":
+		footer = t.status
+	case t.view != nil:
+		footer = "j/k scroll  r read  s star  q back"
+	default:
+		footer = "j/k move  enter open  r read  s star  l list  / search  R refresh  q quit"
+	}
+	return t.f.printer.ColorForeground(runewidth.Truncate(footer, width, "..."), mapColor(7, t.config))
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ParseBookmarks parses a Netscape bookmark file. Every HTTP link is a feed,
+// the folders (H3) it is nested in are its folder and the TAGS attribute, if
+// any, are its tags.
+func ParseBookmarks(r io.Reader) ([]*Subscription, error) {
+	subs := make([]*Subscription, 0)
+	folders := make([]string, 0)
+	// The last folder is only entered once its list (DL) starts
+	pending := ""
+	var link *Subscription
+	var text strings.Builder
+	var heading *strings.Builder
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return subs, nil
+			}
+			return nil, z.Err()
+		case html.StartTagToken:
+			t := z.Token()
+			switch t.Data {
+			case "h3":
+				heading = &strings.Builder{}
+			case "dl":
+				folders = append(folders, pending)
+				pending = ""
+			case "a":
+				href := attr(t, "href")
+				if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+					continue
+				}
+				pending = ""
+				link = &Subscription{
+					URL:    href,
+					Folder: joinFolders(folders),
+					Tags:   ParseTags(attr(t, "tags")),
+				}
+				text.Reset()
+			}
+		case html.EndTagToken:
+			t := z.Token()
+			switch t.Data {
+			case "h3":
+				if heading != nil {
+					pending = heading.String()
+					heading = nil
+				}
+			case "dl":
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case "a":
+				if link != nil {
+					link.Title = strings.TrimSpace(text.String())
+					subs = append(subs, link)
+					link = nil
+				}
+			}
+		case html.TextToken:
+			if heading != nil {
+				heading.Write(z.Text())
+			} else if link != nil {
+				text.Write(z.Text())
+			}
+		}
+	}
+}
+
+func joinFolders(folders []string) string {
+	folder := ""
+	for _, name := range folders {
+		folder = JoinFolder(folder, name)
+	}
+	return folder
+}
+
+func attr(t html.Token, key string) string {
+	for _, a := range t.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
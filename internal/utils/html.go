@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockTags are the tags that start a new line in the text of an HTML document.
+var blockTags = map[string]struct{}{
+	"address":    {},
+	"article":    {},
+	"blockquote": {},
+	"br":         {},
+	"div":        {},
+	"dd":         {},
+	"dt":         {},
+	"figcaption": {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"p":          {},
+	"pre":        {},
+	"section":    {},
+	"table":      {},
+	"tr":         {},
+}
+
+// HTMLToText returns the text of an HTML fragment, e.g. the content of a feed
+// item. Block elements are separated by line breaks, list items start with
+// "- " and scripts and styles are removed.
+func HTMLToText(s string) string {
+	b := new(strings.Builder)
+	skip := 0
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return cleanText(b.String())
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if tag == "script" || tag == "style" {
+				if tt == html.StartTagToken {
+					skip++
+				} else if tt == html.EndTagToken && skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if tag == "li" {
+				// Only the start of a list item, so items are not separated by
+				// empty lines
+				if tt == html.StartTagToken {
+					b.WriteString("\n- ")
+				}
+				continue
+			}
+			if _, ok := blockTags[tag]; ok {
+				b.WriteString("\n")
+			}
+		}
+	}
+}
+
+// cleanText collapses the spaces in each line and consecutive empty lines.
+func cleanText(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, line)
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
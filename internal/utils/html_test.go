@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTMLToText(t *testing.T) {
+	assert.Equal(t, "First & second\nline", HTMLToText("<p>First &nbsp;&amp;  second<br>line</p>"))
+	assert.Equal(t, "Title\n\nText\n\n- one\n- two", HTMLToText(`<h1>Title</h1><style>p { color: red; }</style><p>Text</p><ul><li>one</li><li>two</li></ul><script>alert(1)</script>`))
+	assert.Equal(t, "plain text", HTMLToText("plain   text"))
+	assert.Equal(t, "", HTMLToText(""))
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ParseNewsboatURLs parses a newsboat urls file. Each line is a feed URL
+// followed by its tags. A tag starting with "~" overrides the title of the
+// feed and tags starting with "!" hide the feed in newsboat, so they are
+// ignored. Query feeds and feeds that are not fetched over HTTP are skipped.
+// The first tag of a feed is its folder.
+func ParseNewsboatURLs(r io.Reader) ([]*Subscription, error) {
+	subs := make([]*Subscription, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := splitNewsboatLine(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "http://") && !strings.HasPrefix(fields[0], "https://") {
+			continue
+		}
+		sub := &Subscription{URL: fields[0]}
+		tags := make([]string, 0)
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "~") {
+				sub.Title = field[1:]
+				continue
+			}
+			if strings.HasPrefix(field, "!") {
+				continue
+			}
+			tags = append(tags, field)
+		}
+		if len(tags) > 0 {
+			sub.Folder = JoinFolder("", tags[0])
+		}
+		sub.Tags = ParseTags(strings.Join(tags, ","))
+		subs = append(subs, sub)
+	}
+	return subs, scanner.Err()
+}
+
+// splitNewsboatLine splits a line by whitespace. Double quoted fields may
+// contain whitespace and backslash escaped quotes. A "#" outside of quotes
+// starts a comment.
+func splitNewsboatLine(line string) []string {
+	fields := make([]string, 0)
+	var field strings.Builder
+	inField, quoted, escaped := false, false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inField = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		case !quoted && r == '#' && !inField:
+			return fields
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
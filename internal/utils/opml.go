@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/xml"
+	"strings"
 )
 
 type OPML struct {
@@ -21,5 +22,70 @@ type Body struct {
 
 type Outline struct {
 	Outlines []*Outline `xml:"outline"`
+	Text     string     `xml:"text,attr"`
+	Title    string     `xml:"title,attr,omitempty"`
+	Type     string     `xml:"type,attr,omitempty"`
 	XMLURL   string     `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string     `xml:"htmlUrl,attr,omitempty"`
+	Category string     `xml:"category,attr,omitempty"`
+}
+
+// FeedURLs returns the unique feed URLs of all outlines, including nested ones.
+func (o *OPML) FeedURLs() []string {
+	urls := make([]string, 0)
+	seen := make(map[string]struct{})
+	var walk func(outlines []*Outline)
+	walk = func(outlines []*Outline) {
+		for _, outline := range outlines {
+			if outline.XMLURL != "" {
+				if _, ok := seen[outline.XMLURL]; !ok {
+					seen[outline.XMLURL] = struct{}{}
+					urls = append(urls, outline.XMLURL)
+				}
+			}
+			walk(outline.Outlines)
+		}
+	}
+	walk(o.Body.Oultines)
+	return urls
+}
+
+// Name returns the title of the outline, or its text if it has no title.
+func (o *Outline) Name() string {
+	if o.Title != "" {
+		return o.Title
+	}
+	return o.Text
+}
+
+// Subscriptions returns the feeds of all outlines. The outlines without a feed
+// URL are folders. If the body has a single folder, it is not included in the
+// folder of the feeds.
+func (o *OPML) Subscriptions() []*Subscription {
+	outlines := o.Body.Oultines
+	if len(outlines) == 1 && outlines[0].XMLURL == "" {
+		outlines = outlines[0].Outlines
+	}
+	subs := make([]*Subscription, 0)
+	var walk func(outlines []*Outline, folder string)
+	walk = func(outlines []*Outline, folder string) {
+		for _, outline := range outlines {
+			if outline.XMLURL == "" {
+				walk(outline.Outlines, JoinFolder(folder, outline.Name()))
+				continue
+			}
+			sub := &Subscription{
+				URL:    outline.XMLURL,
+				Site:   outline.HTMLURL,
+				Folder: folder,
+				Tags:   ParseTags(outline.Category),
+			}
+			if title := strings.TrimSpace(outline.Name()); title != outline.XMLURL {
+				sub.Title = title
+			}
+			subs = append(subs, sub)
+		}
+	}
+	walk(outlines, "")
+	return subs
 }
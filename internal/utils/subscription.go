@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Subscription is a feed imported from another feed reader.
+type Subscription struct {
+	URL    string
+	Title  string
+	Site   string   // URL of the website of the feed
+	Folder string   // "/" separated path of the folder of the feed, if any
+	Tags   []string // lowercase tags, see ParseTags
+}
+
+// JoinFolder returns the path of a folder nested under parent. Slashes in the
+// name are replaced, so a folder is always a single part of the path.
+func JoinFolder(parent, name string) string {
+	name = strings.NewReplacer("/", "-", "\\", "-").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return parent
+	}
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
+
+type jsonSubscriptions struct {
+	Subscriptions []*jsonSubscription `json:"subscriptions"`
+}
+
+// jsonSubscription is a feed of a Miniflux (/v1/feeds) or Google Reader API
+// (subscription/list, e.g. FreshRSS) export.
+type jsonSubscription struct {
+	ID         json.RawMessage `json:"id"`
+	FeedURL    string          `json:"feed_url"`
+	URL        string          `json:"url"`
+	Title      string          `json:"title"`
+	SiteURL    string          `json:"site_url"`
+	HTMLURL    string          `json:"htmlUrl"`
+	Category   json.RawMessage `json:"category"`
+	Categories []struct {
+		Label string `json:"label"`
+	} `json:"categories"`
+}
+
+// ParseJSONSubscriptions parses a JSON export of feeds. Both an array of
+// Miniflux feeds and a Google Reader API subscription list are accepted. The
+// category of a feed is its folder.
+func ParseJSONSubscriptions(r io.Reader) ([]*Subscription, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	feeds := make([]*jsonSubscription, 0)
+	if trimmed := strings.TrimSpace(string(b)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(b, &feeds)
+	} else {
+		list := &jsonSubscriptions{}
+		err = json.Unmarshal(b, list)
+		feeds = list.Subscriptions
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	subs := make([]*Subscription, 0, len(feeds))
+	for _, feed := range feeds {
+		sub := &Subscription{
+			URL:   feed.FeedURL,
+			Title: feed.Title,
+			Site:  feed.SiteURL,
+		}
+		if sub.URL == "" {
+			sub.URL = feed.URL
+		}
+		if sub.URL == "" {
+			var id string
+			if json.Unmarshal(feed.ID, &id) == nil {
+				sub.URL = strings.TrimPrefix(id, "feed/")
+			}
+		}
+		if sub.Site == "" {
+			sub.Site = feed.HTMLURL
+		}
+		if len(feed.Categories) > 0 {
+			sub.Folder = JoinFolder("", feed.Categories[0].Label)
+		} else if len(feed.Category) > 0 {
+			category := &struct {
+				Title string `json:"title"`
+			}{}
+			if json.Unmarshal(feed.Category, category) == nil {
+				sub.Folder = JoinFolder("", category.Title)
+			} else {
+				var title string
+				json.Unmarshal(feed.Category, &title)
+				sub.Folder = JoinFolder("", title)
+			}
+		}
+		if sub.URL == "" {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// ParseURLs parses a file with one feed URL per line. Blank lines and lines
+// starting with "#" are skipped.
+func ParseURLs(r io.Reader) ([]*Subscription, error) {
+	subs := make([]*Subscription, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		subs = append(subs, &Subscription{URL: line})
+	}
+	return subs, scanner.Err()
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseNewsboatURLs(t *testing.T) {
+	subs, err := ParseNewsboatURLs(strings.NewReader(`# comment
+https://example.com/feed Go "release notes" "~Example \"Blog\""
+https://hidden.com/feed ! tech # trailing comment
+"query:Unread:unread = \"yes\""
+exec:~/bin/feed.sh
+
+https://plain.com/feed
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Subscription{
+		{URL: "https://example.com/feed", Title: `Example "Blog"`, Folder: "Go", Tags: []string{"go", "release notes"}},
+		{URL: "https://hidden.com/feed", Folder: "tech", Tags: []string{"tech"}},
+		{URL: "https://plain.com/feed", Tags: []string{}},
+	}, subs)
+}
+
+func Test_ParseBookmarks(t *testing.T) {
+	subs, err := ParseBookmarks(strings.NewReader(`<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><A HREF="https://example.com/feed" TAGS="go,news">Example</A>
+    <DT><H3>Tech</H3>
+    <DL><p>
+        <DT><H3>Go/Rust</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev/blog/feed.atom">Go Blog</A>
+        </DL><p>
+        <DT><A HREF="javascript:void(0)">Bookmarklet</A>
+        <DT><A HREF="https://tech.com/rss?a=1&amp;b=2">Tech &amp; Co</A>
+    </DL><p>
+</DL><p>`))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Subscription{
+		{URL: "https://example.com/feed", Title: "Example", Tags: []string{"go", "news"}},
+		{URL: "https://go.dev/blog/feed.atom", Title: "Go Blog", Folder: "Tech/Go-Rust", Tags: []string{}},
+		{URL: "https://tech.com/rss?a=1&b=2", Title: "Tech & Co", Folder: "Tech", Tags: []string{}},
+	}, subs)
+}
+
+func Test_ParseJSONSubscriptions(t *testing.T) {
+	subs, err := ParseJSONSubscriptions(strings.NewReader(`[
+  {"id": 1, "feed_url": "https://example.com/feed", "site_url": "https://example.com", "title": "Example", "category": {"id": 2, "title": "News"}},
+  {"id": 3, "feed_url": "https://test.com/feed", "title": "Test"}
+]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Subscription{
+		{URL: "https://example.com/feed", Title: "Example", Site: "https://example.com", Folder: "News"},
+		{URL: "https://test.com/feed", Title: "Test"},
+	}, subs)
+
+	subs, err = ParseJSONSubscriptions(strings.NewReader(`{"subscriptions": [
+  {"id": "feed/https://example.com/feed", "title": "Example", "htmlUrl": "https://example.com", "categories": [{"id": "user/-/label/Tech", "label": "Tech"}]},
+  {"id": "feed/2", "url": "https://test.com/feed", "title": "Test", "categories": []}
+]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Subscription{
+		{URL: "https://example.com/feed", Title: "Example", Site: "https://example.com", Folder: "Tech"},
+		{URL: "https://test.com/feed", Title: "Test"},
+	}, subs)
+
+	_, err = ParseJSONSubscriptions(strings.NewReader(`{"subscriptions": 1}`))
+	assert.EqualError(t, err, "failed to parse JSON: json: cannot unmarshal number into Go struct field jsonSubscriptions.subscriptions of type []*utils.jsonSubscription")
+}
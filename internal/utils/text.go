@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 	"unicode"
 )
 
@@ -13,6 +15,20 @@ func Pluralize(count int64, singular string) string {
 	return fmt.Sprintf("%d %ss", count, singular)
 }
 
+func FormatBytes(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	units := []string{"KB", "MB", "GB", "TB"}
+	v := float64(n) / 1024
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
 func Tokenize(s string, tokens [][]rune) [][]rune {
 	var token []rune
 	for _, r := range s {
@@ -77,3 +93,18 @@ func LevenshteinDistance(a, b []rune) int {
 	}
 	return row[la]
 }
+
+// ParseTags returns the unique, lowercase tags of a comma separated string.
+// Leading and trailing slashes are removed, so OPML categories such as
+// "/Go,/Security" can be used as tags.
+func ParseTags(s string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.ToLower(strings.Trim(strings.TrimSpace(tag), "/"))
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// trackingParams are query parameters that do not change the content of a
+// feed. Parameters starting with "utm_" are removed as well.
+var trackingParams = []string{"fbclid", "gclid", "mc_cid", "mc_eid", "ref_src", "_hsenc", "_hsmi"}
+
+// NormalizeURL returns the URL with a lowercase scheme and host and without
+// the default port, the fragment and tracking parameters. The URL must be
+// absolute.
+func NormalizeURL(s string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid URL: %s", s)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Scheme == "http" && u.Port() == "80" || u.Scheme == "https" && u.Port() == "443" {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.RawQuery != "" {
+		q := u.Query()
+		removed := false
+		for k := range q {
+			if strings.HasPrefix(k, "utm_") || slices.Contains(trackingParams, k) {
+				q.Del(k)
+				removed = true
+			}
+		}
+		if removed {
+			u.RawQuery = q.Encode()
+		}
+	}
+	return u.String(), nil
+}
+
+// URLKey returns the key used to find duplicate feeds. URLs with the same key
+// only differ by scheme, a "www." prefix, a trailing slash or by what
+// NormalizeURL removes.
+func URLKey(s string) string {
+	n, err := NormalizeURL(s)
+	if err != nil {
+		return s
+	}
+	u, err := url.Parse(n)
+	if err != nil {
+		return n
+	}
+	key := strings.TrimPrefix(u.Host, "www.") + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NormalizeURL(t *testing.T) {
+	u, err := NormalizeURL("HTTPS://Example.com:443/Feed?utm_source=x&id=1&fbclid=2#top")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/Feed?id=1", u)
+
+	u, err = NormalizeURL("http://example.com:8080/feed?b=2&a=1")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com:8080/feed?b=2&a=1", u)
+
+	_, err = NormalizeURL("example.com/feed")
+	assert.EqualError(t, err, "invalid URL: example.com/feed")
+}
+
+func Test_URLKey(t *testing.T) {
+	assert.Equal(t, "example.com/feed", URLKey("http://www.example.com/feed/"))
+	assert.Equal(t, "example.com/feed", URLKey("https://example.com/feed#top"))
+	assert.Equal(t, "example.com/feed?id=1", URLKey("https://example.com/feed?id=1&utm_medium=rss"))
+	assert.NotEqual(t, URLKey("https://example.com/feed"), URLKey("https://example.com/Feed"))
+}